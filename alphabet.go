@@ -1,65 +1,161 @@
 package presents
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
 	"errors"
-	"math"
+	"fmt"
+	"hash"
+	"math/bits"
 	"math/rand"
 	"strings"
+	"unicode/utf8"
 )
 
-type alphabet string
+// Alphabet is the default Encoder, which writes integers in the base given by its number of characters,
+// using its characters as the digits.
+// There must be at least two characters, each of which must be ASCII and appear only once.
+type Alphabet string
 
-func newAlphabet(s string) (alphabet, error) {
-	uniq := make(map[rune]struct{})
-	for _, c := range s {
+func newAlphabet(s string) (Alphabet, error) {
+	uniq := make(map[byte]struct{})
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= utf8.RuneSelf {
+			r, _ := utf8.DecodeRuneInString(s[i:])
+			return "", fmt.Errorf("presents: alphabet must only contain ASCII characters, not %q", r)
+		}
 		if _, ok := uniq[c]; ok {
 			return "", errors.New("presents: all characters in alphabet must be unique")
 		}
 		uniq[c] = struct{}{}
 	}
-	return alphabet(s), nil
+	if len(uniq) < 2 {
+		return "", errors.New("presents: alphabet must contain at least two characters")
+	}
+	return Alphabet(s), nil
 }
 
 // Shuffle returns a new alphabet based on the shuffled characters of a
-func (a alphabet) Shuffle(seed int64) alphabet {
+func (a Alphabet) Shuffle(seed int64) Alphabet {
 	r := rand.New(rand.NewSource(seed))
 	dst := make([]byte, len(a))
 	perm := r.Perm(len(a))
 	for i, j := range perm {
 		dst[j] = byte(a[i])
 	}
-	return alphabet(dst)
+	return Alphabet(dst)
+}
+
+// KeyedShuffle returns a new alphabet based on the characters of a permuted by a Fisher–Yates shuffle,
+// which draws its random numbers from HMAC-SHA256 keyed with key.
+// Unlike Shuffle, the result does not depend on the math/rand algorithms.
+func (a Alphabet) KeyedShuffle(key []byte) Alphabet {
+	r := newPRFReader(key)
+	dst := []byte(a)
+	for i := len(dst) - 1; i > 0; i-- {
+		j := r.Intn(i + 1)
+		dst[i], dst[j] = dst[j], dst[i]
+	}
+	return Alphabet(dst)
+}
+
+// prfReader produces a stream of pseudorandom numbers by computing
+// HMAC-SHA256(key, "presents keyed shuffle" || counter) for successive 64-bit big-endian counters.
+type prfReader struct {
+	mac     hash.Hash
+	counter uint64
+	buf     []byte
+}
+
+func newPRFReader(key []byte) *prfReader {
+	return &prfReader{mac: hmac.New(sha256.New, key)}
+}
+
+// Uint32 returns the next 32 bits of the stream.
+func (r *prfReader) Uint32() uint32 {
+	if len(r.buf) < 4 {
+		r.mac.Reset()
+		r.mac.Write([]byte("presents keyed shuffle"))
+		var ctr [8]byte
+		binary.BigEndian.PutUint64(ctr[:], r.counter)
+		r.mac.Write(ctr[:])
+		r.counter++
+		r.buf = r.mac.Sum(nil)
+	}
+	x := binary.BigEndian.Uint32(r.buf)
+	r.buf = r.buf[4:]
+	return x
 }
 
-func (a alphabet) Encode(n uint64) string {
+// Intn returns a uniformly distributed integer in [0, n), rejecting samples which would bias the result.
+func (r *prfReader) Intn(n int) int {
+	max := uint32(n)
+	limit := ^uint32(0) - ^uint32(0)%max
+	for {
+		x := r.Uint32()
+		if x < limit {
+			return int(x % max)
+		}
+	}
+}
+
+// Encode converts n to a string, least significant digit first.
+func (a Alphabet) Encode(n uint64) string {
+	return string(a.AppendEncode(make([]byte, 0, a.encodedLen(n)), n))
+}
+
+// AppendEncode appends the result of Encode to dst and returns the extended buffer.
+func (a Alphabet) AppendEncode(dst []byte, n uint64) []byte {
 	b := uint64(len(a))
-	s := make([]byte, a.encodedLen(n))
-	for i := 0; n > 0; i++ {
-		s[i] = a[n%b]
+	for i := a.encodedLen(n); i > 0; i-- {
+		dst = append(dst, a[n%b])
 		n /= b
 	}
-	return string(s)
+	return dst
 }
 
-func (a alphabet) Decode(s string) (uint64, error) {
-	b := len(a)
-	mag := 1
+// Decode converts a string produced by Encode back to an integer.
+// It returns ErrLength for an empty string, an *InvalidCharError for characters outside the alphabet,
+// and ErrOverflow if the value does not fit in 64 bits.
+func (a Alphabet) Decode(s string) (uint64, error) {
+	if s == "" {
+		return 0, ErrLength
+	}
+	b := uint64(len(a))
+	mag := uint64(1)
+	// magOverflow is set once mag no longer fits in 64 bits,
+	// after which only zero digits are allowed
+	magOverflow := false
 	var n uint64
-	for _, c := range []byte(s) {
-		x := strings.IndexByte(string(a), c)
+	for i := 0; i < len(s); i++ {
+		x := strings.IndexByte(string(a), s[i])
 		if x == -1 {
-			return 0, errors.New("presents: Unwrap: invalid input")
+			c, _ := utf8.DecodeRuneInString(s[i:])
+			return 0, &InvalidCharError{Pos: i, Char: c}
+		}
+		if x != 0 {
+			hi, lo := bits.Mul64(uint64(x), mag)
+			var carry uint64
+			n, carry = bits.Add64(n, lo, 0)
+			if magOverflow || hi != 0 || carry != 0 {
+				return 0, ErrOverflow
+			}
 		}
-		n += uint64(x * mag)
-		mag *= b
+		var hi uint64
+		hi, mag = bits.Mul64(mag, b)
+		magOverflow = magOverflow || hi != 0
 	}
 	return n, nil
 }
 
-func (a alphabet) encodedLen(n uint64) int {
-	p := math.Log(float64(n))
-	q := math.Log(float64(len(a)))
-	r := p / q
-	s := math.Ceil(r)
-	return int(s)
+// encodedLen returns the number of digits needed to encode n, which is at least 1.
+func (a Alphabet) encodedLen(n uint64) int {
+	b := uint64(len(a))
+	l := 1
+	for n /= b; n > 0; n /= b {
+		l++
+	}
+	return l
 }
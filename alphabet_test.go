@@ -1,11 +1,27 @@
 package presents
 
 import (
+	"crypto/des"
+	"encoding/binary"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 )
 
+func TestNewAlphabet(t *testing.T) {
+	a, err := newAlphabet("01")
+	assert.NoError(t, err)
+	assert.Equal(t, Alphabet("01"), a)
+	for _, s := range []string{"", "a"} {
+		_, err := newAlphabet(s)
+		assert.EqualError(t, err, "presents: alphabet must contain at least two characters", s)
+	}
+	_, err = newAlphabet("aa")
+	assert.EqualError(t, err, "presents: all characters in alphabet must be unique")
+	_, err = newAlphabet("abcé")
+	assert.EqualError(t, err, `presents: alphabet must only contain ASCII characters, not 'é'`)
+}
+
 func TestAlphabet_Encode(t *testing.T) {
 	s := DefaultAlphabet.Encode(1213486160)
 	expected := "QBf7K1"
@@ -32,3 +48,60 @@ func TestAlphabet_Shuffle(t *testing.T) {
 	expected := "gd2J1bExUClwVnmNXoB6H0ifMqGKLkpz5cv8O9RQhAWrS7s4D3IujFePTatZyY"
 	assert.Equal(t, expected, string(shuffled))
 }
+
+// The expected permutation is frozen: changing it would change the output of every codec using KeyedShuffle.
+func TestAlphabet_KeyedShuffle(t *testing.T) {
+	shuffled := DefaultAlphabet.KeyedShuffle([]byte("presents"))
+	expected := "qNn0Wtg2SaGzs5ZIrpMfedHlVUA7TkBE6m1CuocxY8bXyv9OL3DjQJFiRK4Pwh"
+	assert.Equal(t, expected, string(shuffled))
+	assert.Equal(t, shuffled, DefaultAlphabet.KeyedShuffle([]byte("presents")))
+	assert.NotEqual(t, shuffled, DefaultAlphabet.KeyedShuffle([]byte("presents2")))
+}
+
+func TestKeyedShuffle_secret(t *testing.T) {
+	key := make([]byte, 24)
+	p, err := NewTripleDES(key, WithKeyedShuffle())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, DefaultAlphabet.KeyedShuffle(deriveSecret(key)), p.alphabet)
+
+	// without the key, the secret comes from decryptions, which wrapped strings do not reveal
+	c, err := des.NewTripleDESCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, err := NewWithCipher(c, WithKeyedShuffle())
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := make([]byte, 8*len(secretBlocks))
+	for i, b := range secretBlocks {
+		binary.BigEndian.PutUint64(secret[8*i:], b)
+		c.Decrypt(secret[8*i:8*i+8], secret[8*i:8*i+8])
+	}
+	assert.Equal(t, DefaultAlphabet.KeyedShuffle(secret), q.alphabet)
+}
+
+func TestPRFReader_Intn(t *testing.T) {
+	r := newPRFReader([]byte("presents"))
+	counts := make([]int, 3)
+	for i := 0; i < 3000; i++ {
+		counts[r.Intn(3)]++
+	}
+	for _, c := range counts {
+		assert.InDelta(t, 1000, c, 150)
+	}
+	assert.Equal(t, 0, r.Intn(1))
+}
+
+func TestAlphabet_EncodedLen_powers(t *testing.T) {
+	hex := Alphabet("0123456789abcdef")
+	assert.Equal(t, 1, hex.encodedLen(0))
+	assert.Equal(t, "0", hex.Encode(0))
+	assert.Equal(t, 2, hex.encodedLen(16))
+	assert.Equal(t, 16, hex.encodedLen(^uint64(0)))
+	assert.Equal(t, "01", hex.Encode(16))
+	assert.Equal(t, 2, DefaultAlphabet.encodedLen(62))
+	assert.Equal(t, 11, DefaultAlphabet.encodedLen(^uint64(0)))
+}
@@ -0,0 +1,130 @@
+package presents
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// AlphabetFlags modify the alphabet built by BuildAlphabet.
+type AlphabetFlags uint
+
+const (
+	// ExcludeAmbiguous removes characters which are easily confused with each other: 0, O, 1, l and I.
+	ExcludeAmbiguous AlphabetFlags = 1 << iota
+	// ExcludeVowels removes a, e, i, o and u in both cases, which makes accidental words much less likely.
+	ExcludeVowels
+	// CaseInsensitive folds letters to lowercase and removes the resulting duplicates,
+	// so that strings survive case-insensitive storage or comparison when the input to Unwrap is lowercased first.
+	CaseInsensitive
+)
+
+const (
+	ambiguousChars = "0O1lI"
+	vowelChars     = "aeiouAEIOU"
+)
+
+// BuildAlphabet builds an alphabet from a spec, for use as Options.Alphabet.
+//
+// A spec is a list of characters and ranges of characters such as "a-z0-9",
+// optionally separated by commas as in "A-Z,2-9".
+// A '-' at the start or end of a spec or next to a comma is a literal '-',
+// and a backslash makes the following character literal, so "\\," is a comma.
+// Characters appear in the alphabet in the order they appear in the spec.
+//
+// Every character must be printable ASCII other than space, must not appear in the spec more than once,
+// and at least two characters must be left after applying flags.
+// Otherwise BuildAlphabet returns an error explaining which character was rejected and why.
+func BuildAlphabet(spec string, flags AlphabetFlags) (string, error) {
+	chars, err := parseAlphabetSpec(spec)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	seen := make(map[byte]int)
+	for _, c := range chars {
+		if first, ok := seen[c.char]; ok {
+			return "", fmt.Errorf("presents: alphabet spec %q: %q at position %d repeats %q at position %d",
+				spec, c.char, c.pos, c.char, first)
+		}
+		seen[c.char] = c.pos
+		ch := c.char
+		if flags&CaseInsensitive != 0 && 'A' <= ch && ch <= 'Z' {
+			ch += 'a' - 'A'
+		}
+		// exclusions apply to characters both before and after folding, so 'L' is dropped with 'l'
+		switch {
+		case flags&ExcludeAmbiguous != 0 && strings.ContainsAny(ambiguousChars, string([]byte{c.char, ch})):
+			continue
+		case flags&ExcludeVowels != 0 && strings.IndexByte(vowelChars, ch) != -1:
+			continue
+		case strings.IndexByte(b.String(), ch) != -1:
+			continue
+		}
+		b.WriteByte(ch)
+	}
+	if b.Len() < 2 {
+		return "", fmt.Errorf("presents: alphabet spec %q: %d characters left, need at least 2", spec, b.Len())
+	}
+	return b.String(), nil
+}
+
+// specChar is a character from an alphabet spec and the byte offset it came from.
+type specChar struct {
+	char byte
+	pos  int
+}
+
+// parseAlphabetSpec expands the ranges in spec and checks that every character is allowed.
+func parseAlphabetSpec(spec string) ([]specChar, error) {
+	if spec == "" {
+		return nil, errors.New("presents: alphabet spec is empty")
+	}
+	// unescape first, remembering which characters were escaped
+	type token struct {
+		specChar
+		literal bool
+	}
+	var tokens []token
+	for i := 0; i < len(spec); i++ {
+		pos, literal := i, false
+		if spec[i] == '\\' {
+			if i+1 == len(spec) {
+				return nil, fmt.Errorf("presents: alphabet spec %q: trailing backslash", spec)
+			}
+			i++
+			literal = true
+		}
+		c := spec[i]
+		if c <= ' ' || c > '~' {
+			return nil, fmt.Errorf("presents: alphabet spec %q: %q at position %d is not printable ASCII", spec, rune(c), i)
+		}
+		tokens = append(tokens, token{specChar{c, pos}, literal})
+	}
+
+	var chars []specChar
+	isOp := func(i int, op byte) bool {
+		return 0 <= i && i < len(tokens) && tokens[i].char == op && !tokens[i].literal
+	}
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+		if isOp(i, ',') {
+			continue
+		}
+		// a range needs a character on either side of the '-'
+		if i+2 < len(tokens) && isOp(i+1, '-') && !isOp(i+2, ',') {
+			end := tokens[i+2]
+			if end.char < t.char {
+				return nil, fmt.Errorf("presents: alphabet spec %q: range %c-%c at position %d is backwards",
+					spec, t.char, end.char, t.pos)
+			}
+			for c := int(t.char); c <= int(end.char); c++ {
+				chars = append(chars, specChar{byte(c), t.pos})
+			}
+			i += 2
+			continue
+		}
+		chars = append(chars, t.specChar)
+	}
+	return chars, nil
+}
@@ -0,0 +1,71 @@
+package presents_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yi-jiayu/presents"
+)
+
+func TestBuildAlphabet(t *testing.T) {
+	testCases := []struct {
+		spec     string
+		flags    presents.AlphabetFlags
+		expected string
+	}{
+		{"0-9A-Za-z", 0, string(presents.DefaultAlphabet)},
+		{"a-f0-9", 0, "abcdef0123456789"},
+		{"A-Z,2-9", 0, "ABCDEFGHIJKLMNOPQRSTUVWXYZ23456789"},
+		{"0-9a-z", presents.ExcludeAmbiguous, "23456789abcdefghijkmnopqrstuvwxyz"},
+		{"a-z", presents.ExcludeVowels, "bcdfghjklmnpqrstvwxyz"},
+		{"A-Za-z", presents.CaseInsensitive, "abcdefghijklmnopqrstuvwxyz"},
+		{"0-9A-Za-z", presents.ExcludeAmbiguous | presents.ExcludeVowels | presents.CaseInsensitive,
+			"23456789bcdfghjkmnpqrstvwxyz"},
+		{"-ab", 0, "-ab"},
+		{`a\-c,x\,`, 0, "a-cx,"},
+		{"a-,b", 0, "a-b"},
+	}
+	for _, tc := range testCases {
+		actual, err := presents.BuildAlphabet(tc.spec, tc.flags)
+		if assert.NoError(t, err, "%q", tc.spec) {
+			assert.Equal(t, tc.expected, actual, "%q", tc.spec)
+		}
+	}
+}
+
+func TestBuildAlphabet_errors(t *testing.T) {
+	testCases := []struct {
+		spec  string
+		flags presents.AlphabetFlags
+		err   string
+	}{
+		{"", 0, `presents: alphabet spec is empty`},
+		{"a-zx", 0, `presents: alphabet spec "a-zx": 'x' at position 3 repeats 'x' at position 0`},
+		{"z-a", 0, `presents: alphabet spec "z-a": range z-a at position 0 is backwards`},
+		{"a-c é", 0, `presents: alphabet spec "a-c é": ' ' at position 3 is not printable ASCII`},
+		{"abé", 0, `presents: alphabet spec "abé": 'Ã' at position 2 is not printable ASCII`},
+		{`ab\`, 0, `presents: alphabet spec "ab\\": trailing backslash`},
+		{"aeiou", presents.ExcludeVowels, `presents: alphabet spec "aeiou": 0 characters left, need at least 2`},
+	}
+	for _, tc := range testCases {
+		_, err := presents.BuildAlphabet(tc.spec, tc.flags)
+		assert.EqualError(t, err, tc.err, "%q", tc.spec)
+	}
+}
+
+func TestBuildAlphabet_options(t *testing.T) {
+	a, err := presents.BuildAlphabet("0-9a-z", presents.ExcludeAmbiguous|presents.ExcludeVowels)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := presents.New(make([]byte, 10), &presents.Options{Alphabet: a})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := p.Wrap(1213486160)
+	assert.NotContains(t, s, "a")
+	assert.NotContains(t, s, "0")
+	n, err := p.Unwrap(s)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1213486160), n)
+}
@@ -0,0 +1,155 @@
+package presents
+
+// presentRounds is the number of rounds in the PRESENT block cipher.
+const presentRounds = 31
+
+// presentSBox is the PRESENT 4-bit substitution box.
+var presentSBox = [16]uint64{0xC, 5, 6, 0xB, 9, 0, 0xA, 0xD, 3, 0xE, 0xF, 8, 4, 7, 1, 2}
+
+// bitslicedPresent implements PRESENT encryption over 64 blocks at a time.
+//
+// Bit j of every block is stored in a single 64-bit word, so the S-box layer becomes a handful of
+// boolean operations on whole words and the permutation layer becomes a relabelling of words.
+type bitslicedPresent struct {
+	roundKeys [presentRounds + 1]uint64
+}
+
+// newBitslicedPresent expands an 80-bit or 128-bit PRESENT key.
+// It returns nil if the key has any other length.
+func newBitslicedPresent(key []byte) *bitslicedPresent {
+	var b bitslicedPresent
+	switch len(key) {
+	case 10:
+		b.roundKeys = expandKey80(key)
+	case 16:
+		b.roundKeys = expandKey128(key)
+	default:
+		return nil
+	}
+	return &b
+}
+
+// expandKey80 derives the PRESENT round keys from an 80-bit key.
+// The key register is held as its top 64 bits in a and its bottom 16 bits in b.
+func expandKey80(key []byte) (roundKeys [presentRounds + 1]uint64) {
+	var a, b uint64
+	for _, x := range key[:8] {
+		a = a<<8 | uint64(x)
+	}
+	b = uint64(key[8])<<8 | uint64(key[9])
+	for i := 0; i < presentRounds; i++ {
+		roundKeys[i] = a
+		// rotate the 80-bit register left by 61
+		a, b = a>>19|((a&7)<<16|b)<<45, (a>>3)&0xffff
+		a = sBoxWord(a, sBoxSliced)&0xf000000000000000 | a&0x0fffffffffffffff
+		ctr := uint64(i + 1)
+		a ^= ctr >> 1
+		b ^= (ctr & 1) << 15
+	}
+	roundKeys[presentRounds] = a
+	return
+}
+
+// expandKey128 derives the PRESENT round keys from a 128-bit key.
+// The key register is held as its top 64 bits in a and its bottom 64 bits in b.
+func expandKey128(key []byte) (roundKeys [presentRounds + 1]uint64) {
+	var a, b uint64
+	for _, x := range key[:8] {
+		a = a<<8 | uint64(x)
+	}
+	for _, x := range key[8:] {
+		b = b<<8 | uint64(x)
+	}
+	for i := 0; i < presentRounds; i++ {
+		roundKeys[i] = a
+		// rotate the 128-bit register left by 61
+		a, b = b>>3|a<<61, a>>3|b<<61
+		a = sBoxWord(a, sBoxSliced)&0xff00000000000000 | a&0x00ffffffffffffff
+		ctr := uint64(i + 1)
+		a ^= ctr >> 2
+		b ^= (ctr & 3) << 62
+	}
+	roundKeys[presentRounds] = a
+	return
+}
+
+// Encrypt64 encrypts 64 blocks in place.
+func (b *bitslicedPresent) Encrypt64(blocks *[64]uint64) {
+	s := *blocks
+	transpose64(&s)
+	var t [64]uint64
+	for r := 0; r < presentRounds; r++ {
+		addRoundKey(&s, b.roundKeys[r])
+		for i := 0; i < 64; i += 4 {
+			s[i], s[i+1], s[i+2], s[i+3] = sBoxSliced(s[i], s[i+1], s[i+2], s[i+3])
+		}
+		for j := 0; j < 63; j++ {
+			t[j*16%63] = s[j]
+		}
+		t[63] = s[63]
+		s = t
+	}
+	addRoundKey(&s, b.roundKeys[presentRounds])
+	transpose64(&s)
+	*blocks = s
+}
+
+// addRoundKey XORs bit j of k into every bit of s[j].
+func addRoundKey(s *[64]uint64, k uint64) {
+	for j := range s {
+		s[j] ^= -(k >> uint(j) & 1)
+	}
+}
+
+// sBoxSliced evaluates the PRESENT S-box on 64 nibbles at once, where x0 holds their least significant bits.
+func sBoxSliced(x0, x1, x2, x3 uint64) (y0, y1, y2, y3 uint64) {
+	x01 := x0 & x1
+	x12 := x1 & x2
+	x13 := x1 & x3
+	x013 := x01 & x3
+	x023 := x0 & x2 & x3
+	y0 = x0 ^ x2 ^ x12 ^ x3
+	y1 = x1 ^ x01&x2 ^ x3 ^ x13 ^ x013 ^ x2&x3 ^ x023
+	y2 = ^(x01 ^ x2 ^ x3 ^ x0&x3 ^ x13 ^ x013 ^ x023)
+	y3 = ^(x0 ^ x1 ^ x12 ^ x0&x12 ^ x3 ^ x013 ^ x023)
+	return
+}
+
+// sBoxInvSliced evaluates the inverse PRESENT S-box on 64 nibbles at once, where x0 holds their least significant bits.
+func sBoxInvSliced(x0, x1, x2, x3 uint64) (y0, y1, y2, y3 uint64) {
+	x01 := x0 & x1
+	x02 := x0 & x2
+	x13 := x1 & x3
+	x012 := x01 & x2
+	x013 := x01 & x3
+	x023 := x02 & x3
+	y0 = ^(x0 ^ x2 ^ x13)
+	y1 = x0 ^ x1 ^ x02 ^ x012 ^ x3 ^ x13 ^ x013 ^ x2&x3 ^ x023
+	y2 = ^(x01 ^ x02 ^ x1&x2 ^ x012 ^ x3 ^ x0&x3 ^ x13 ^ x013 ^ x023)
+	y3 = x0 ^ x1 ^ x01 ^ x2 ^ x012 ^ x3 ^ x023
+	return
+}
+
+// nibbleLanes selects the least significant bit of each nibble.
+const nibbleLanes = 0x1111111111111111
+
+// sBoxWord applies the S-box formula f to all 16 nibbles of s without any table lookups,
+// by treating the bits of s with the same position within their nibble as a bit plane.
+func sBoxWord(s uint64, f func(x0, x1, x2, x3 uint64) (y0, y1, y2, y3 uint64)) uint64 {
+	y0, y1, y2, y3 := f(s&nibbleLanes, s>>1&nibbleLanes, s>>2&nibbleLanes, s>>3&nibbleLanes)
+	return y0&nibbleLanes | (y1&nibbleLanes)<<1 | (y2&nibbleLanes)<<2 | (y3&nibbleLanes)<<3
+}
+
+// transpose64 transposes a 64×64 bit matrix in place,
+// so that bit j of a[k] ends up as bit k of a[j].
+func transpose64(a *[64]uint64) {
+	m := uint64(0x00000000ffffffff)
+	for j := uint(32); j != 0; j >>= 1 {
+		for k := uint(0); k < 64; k = (k | j + 1) &^ j {
+			t := (a[k]>>j ^ a[k|j]) & m
+			a[k] ^= t << j
+			a[k|j] ^= t
+		}
+		m ^= m << (j >> 1)
+	}
+}
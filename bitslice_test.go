@@ -0,0 +1,63 @@
+package presents
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yi-jiayu/PRESENT.go"
+)
+
+func TestTranspose64(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	var a, expected [64]uint64
+	for i := range a {
+		a[i] = r.Uint64()
+	}
+	for j := uint(0); j < 64; j++ {
+		for k := uint(0); k < 64; k++ {
+			expected[j] |= (a[k] >> j & 1) << k
+		}
+	}
+	transpose64(&a)
+	assert.Equal(t, expected, a)
+}
+
+func TestSBoxSliced(t *testing.T) {
+	for x := uint64(0); x < 16; x++ {
+		y0, y1, y2, y3 := sBoxSliced(-(x & 1), -(x >> 1 & 1), -(x >> 2 & 1), -(x >> 3 & 1))
+		for _, y := range []uint64{y0, y1, y2, y3} {
+			assert.True(t, y == 0 || y == ^uint64(0))
+		}
+		y := y0&1 | y1&1<<1 | y2&1<<2 | y3&1<<3
+		assert.Equal(t, presentSBox[x], y, "S(%x)", x)
+	}
+}
+
+func TestBitslicedPresent_Encrypt64(t *testing.T) {
+	for _, keySize := range []int{10, 16} {
+		key := make([]byte, keySize)
+		r := rand.New(rand.NewSource(int64(keySize)))
+		r.Read(key)
+		c, err := present.NewCipher(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b := newBitslicedPresent(key)
+		var blocks, expected [64]uint64
+		src, dst := make([]byte, 8), make([]byte, 8)
+		for i := range blocks {
+			blocks[i] = r.Uint64()
+			binary.BigEndian.PutUint64(src, blocks[i])
+			c.Encrypt(dst, src)
+			expected[i] = binary.BigEndian.Uint64(dst)
+		}
+		b.Encrypt64(&blocks)
+		assert.Equal(t, expected, blocks, "%d-bit key", keySize*8)
+	}
+}
+
+func TestNewBitslicedPresent(t *testing.T) {
+	assert.Nil(t, newBitslicedPresent(make([]byte, 24)))
+}
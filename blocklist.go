@@ -0,0 +1,131 @@
+package presents
+
+import (
+	"strings"
+)
+
+// DefaultBlocklist is a list of English profanity, slurs and other words which should not appear in IDs.
+// It can be passed as Options.Blocklist, either as it is or combined with other words such as brand names.
+var DefaultBlocklist = []string{
+	"anal", "anus", "arse", "ass", "bitch", "boob", "butt", "clit", "cock", "coon", "crap", "cum", "cunt",
+	"damn", "dick", "dildo", "dyke", "fag", "fuck", "hell", "homo", "jizz", "kike", "kkk", "nazi", "nig",
+	"paki", "penis", "piss", "poo", "porn", "pussy", "rape", "retard", "sex", "shit", "slut", "spic",
+	"tit", "twat", "vagina", "wank", "whore",
+}
+
+// newBlocklist lowercases words and removes empty ones.
+// It returns nil if no words are left.
+func newBlocklist(words []string) []string {
+	var blocklist []string
+	for _, w := range words {
+		if w != "" {
+			blocklist = append(blocklist, strings.ToLower(w))
+		}
+	}
+	return blocklist
+}
+
+// blocked reports whether s contains any word in the blocklist, ignoring case.
+func (p *Presents) blocked(s string) bool {
+	s = strings.ToLower(s)
+	for _, w := range p.blocklist {
+		if strings.Contains(s, w) {
+			return true
+		}
+	}
+	return false
+}
+
+// encode converts the encryption x of an integer to a string using the Encoder if there is one,
+// or the alphabet, avoiding words in the blocklist.
+// Strings are checked after padding, since the padding can complete a blocked word.
+//
+// If the encoding of x contains a blocked word, x is encrypted again up to len(alphabet)-1 times,
+// until the result encodes to a clean string.
+// The number of extra encryptions t is recorded by padding the string to maxLen digits
+// and appending the digit t, which never appears there in the output of Encode
+// since maxLen digits are enough for any 64-bit integer.
+// If every attempt is blocked, the encoding of x is returned.
+func (p *Presents) encode(x uint64) string {
+	if p.encoder != nil {
+		return p.encoder.Encode(x)
+	}
+	s := p.alphabet.Encode(x)
+	if p.blocklist == nil || !p.blocked(p.padded(s)) {
+		return s
+	}
+	for t := 1; t < len(p.alphabet); t++ {
+		x = p.encrypt(x)
+		if tweaked := p.tweaked(x, t); !p.blocked(tweaked) {
+			return tweaked
+		}
+	}
+	return s
+}
+
+// decode converts s to an integer using the Encoder if there is one, or the alphabet.
+// It undoes any extra encryptions made by encode to avoid the blocklist.
+func (p *Presents) decode(s string) (uint64, error) {
+	if p.encoder != nil {
+		return p.encoder.Decode(s)
+	}
+	decode := p.alphabet.Decode
+	if p.constantTime {
+		decode = p.alphabet.decodeConstantTime
+	}
+	t, digits := p.untweak(s)
+	n, err := decode(digits)
+	if err != nil || t == 0 {
+		return n, err
+	}
+	return p.decryptTweaked(n, t)
+}
+
+// padded returns s as padded by the Padding decorator, or s if there is none.
+// Tweaked strings are longer than the padding width, so they are never padded.
+func (p *Presents) padded(s string) string {
+	for _, d := range p.decorators {
+		if d, ok := d.(padding); ok {
+			return d.Decorate(s)
+		}
+	}
+	return s
+}
+
+// tweaked returns the string for x after t extra encryptions.
+func (p *Presents) tweaked(x uint64, t int) string {
+	s := p.alphabet.Encode(x)
+	maxLen := p.alphabet.encodedLen(^uint64(0))
+	return s + strings.Repeat(string(p.alphabet[0]), maxLen-len(s)) + string(p.alphabet[t])
+}
+
+// untweak returns the number of extra encryptions recorded at the end of s, and the rest of s.
+// It returns 0 and s if s does not have a tweak digit.
+func (p *Presents) untweak(s string) (int, string) {
+	maxLen := p.alphabet.encodedLen(^uint64(0))
+	if p.blocklist == nil || len(s) != maxLen+1 {
+		return 0, s
+	}
+	t := strings.IndexByte(string(p.alphabet), s[maxLen])
+	if t <= 0 {
+		return 0, s
+	}
+	return t, s[:maxLen]
+}
+
+// decryptTweaked undoes t extra encryptions of x, and checks that encode would have produced
+// the string for x, by making sure that the strings for each of the fewer encryptions are blocked.
+// It returns ErrNonCanonical if they are not.
+func (p *Presents) decryptTweaked(x uint64, t int) (uint64, error) {
+	for i := t - 1; i >= 0; i-- {
+		x = p.decrypt(x)
+		s := p.padded(p.alphabet.Encode(x))
+		if i > 0 {
+			s = p.tweaked(x, i)
+		}
+		if !p.blocked(s) {
+			return 0, ErrNonCanonical
+		}
+	}
+	return x, nil
+}
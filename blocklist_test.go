@@ -0,0 +1,115 @@
+package presents
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPresents_Wrap_blocklist(t *testing.T) {
+	plain, err := New(make([]byte, 10), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// blocking common letters forces some IDs to need several extra encryptions
+	blocklist := []string{"A", "b", "c", "D", "e"}
+	p, err := New(make([]byte, 10), &Options{Blocklist: blocklist})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tweaks := make(map[int]int)
+	for n := uint64(0); n < 2000; n++ {
+		s := p.Wrap(n)
+		assert.NotContains(t, strings.ToLower(s), "a")
+		assert.NotContains(t, strings.ToLower(s), "e")
+		if unblocked := plain.Wrap(n); !p.blocked(unblocked) {
+			assert.Equal(t, unblocked, s)
+		}
+		tweak, _ := p.untweak(s)
+		tweaks[tweak]++
+		actual, err := p.Unwrap(s)
+		assert.NoError(t, err)
+		assert.Equal(t, n, actual)
+	}
+	assert.True(t, len(tweaks) > 2, "tweak counts: %v", tweaks)
+
+	src := make([]uint64, 200)
+	for i := range src {
+		src[i] = uint64(i)
+	}
+	dst := make([]string, len(src))
+	p.WrapBatch(dst, src)
+	for i, n := range src {
+		assert.Equal(t, p.Wrap(n), dst[i])
+	}
+}
+
+func TestPresents_Unwrap_blocklist(t *testing.T) {
+	p, err := New(make([]byte, 10), &Options{Blocklist: DefaultBlocklist})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// find an ID whose usual string is clean
+	n := uint64(0)
+	for p.blocked(p.alphabet.Encode(p.encrypt(n))) {
+		n++
+	}
+	t.Run("non-canonical tweak", func(t *testing.T) {
+		s := p.tweaked(p.encrypt(p.encrypt(n)), 1)
+		_, err := p.Unwrap(s)
+		assert.Equal(t, ErrNonCanonical, err)
+	})
+	t.Run("without blocklist", func(t *testing.T) {
+		plain, err := New(make([]byte, 10), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = plain.Unwrap(p.tweaked(p.encrypt(n), 1))
+		assert.Equal(t, ErrOverflow, err)
+	})
+	t.Run("default blocklist", func(t *testing.T) {
+		for _, w := range DefaultBlocklist {
+			assert.Equal(t, strings.ToLower(w), w)
+		}
+		found := false
+		for n := uint64(0); n < 100000 && !found; n++ {
+			s := p.Wrap(n)
+			assert.False(t, p.blocked(s), "%q", s)
+			if tweak, _ := p.untweak(s); tweak > 0 {
+				found = true
+				actual, err := p.Unwrap(s)
+				assert.NoError(t, err)
+				assert.Equal(t, n, actual)
+			}
+		}
+		assert.True(t, found)
+	})
+}
+
+func TestPresents_Wrap_blocklistPadding(t *testing.T) {
+	p, err := New(make([]byte, 10), &Options{
+		Alphabet:  "abcdefghijklmnopqrstuvwxyz",
+		Padding:   true,
+		Blocklist: []string{"za"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	width := p.alphabet.encodedLen(^uint64(0))
+	for n := uint64(0); n < 2000; n++ {
+		s := p.Wrap(n)
+		assert.NotContains(t, s, "za")
+		if tweak, _ := p.untweak(s); tweak > 0 {
+			assert.Len(t, s, width+1)
+		} else {
+			assert.Len(t, s, width)
+		}
+		actual, err := p.Unwrap(s)
+		assert.NoError(t, err)
+		assert.Equal(t, n, actual)
+	}
+	// the unpadded string for 8 ends in "z", so it is only blocked once padded
+	assert.False(t, p.blocked(p.alphabet.Encode(p.encrypt(8))))
+	assert.True(t, p.blocked(p.padded(p.alphabet.Encode(p.encrypt(8)))))
+}
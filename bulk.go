@@ -0,0 +1,282 @@
+package presents
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// IndexError records an error converting a single element of a bulk operation.
+type IndexError struct {
+	Index int
+	Err   error
+}
+
+func (e *IndexError) Error() string {
+	return fmt.Sprintf("presents: index %d: %v", e.Index, e.Err)
+}
+
+// Unwrap returns the error for the element.
+func (e *IndexError) Unwrap() error {
+	return e.Err
+}
+
+// BulkError is returned by WrapAll and UnwrapAll when one or more elements could not be converted.
+// The errors are sorted by index.
+type BulkError []*IndexError
+
+func (e BulkError) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+	return fmt.Sprintf("presents: %d errors, first: %v", len(e), e[0])
+}
+
+// Unwrap returns the errors for the individual elements, so that errors.Is and errors.As can inspect them.
+func (e BulkError) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+// WrapResult is the result of converting a single integer received by WrapStream.
+// Index is the position of the integer in the input stream, starting from 0.
+type WrapResult struct {
+	Index int
+	S     string
+	Err   error
+}
+
+// UnwrapResult is the result of converting a single string received by UnwrapStream.
+// Index is the position of the string in the input stream, starting from 0.
+type UnwrapResult struct {
+	Index int
+	N     uint64
+	Err   error
+}
+
+// WrapAll converts every integer in src to a string, using up to workers goroutines.
+// The results are in the same order as src.
+// If workers is less than 1, runtime.GOMAXPROCS(0) workers are used.
+//
+// An integer outside the domain of the Permutation does not stop the others from being converted.
+// Its result is left empty and the returned error is a BulkError containing an IndexError for it.
+func (p *Presents) WrapAll(src []uint64, workers int) ([]string, error) {
+	dst := make([]string, len(src))
+	if p.batch != nil {
+		// the batch cipher is 64 bits wide, so every integer is in its domain
+		parallel(len(src), workers, func(lo, hi int) {
+			p.WrapBatch(dst[lo:hi], src[lo:hi])
+		})
+		return dst, nil
+	}
+	err := parallelEach(len(src), workers, func(i int) (err error) {
+		dst[i], err = p.TryWrap(src[i])
+		return err
+	})
+	return dst, err
+}
+
+// UnwrapAll converts every string in src back to an integer, using up to workers goroutines.
+// The results are in the same order as src.
+// If workers is less than 1, runtime.GOMAXPROCS(0) workers are used.
+//
+// A string that cannot be converted does not stop the others from being converted.
+// Its result is left as 0 and the returned error is a BulkError containing an IndexError for it.
+func (p *Presents) UnwrapAll(src []string, workers int) ([]uint64, error) {
+	dst := make([]uint64, len(src))
+	err := parallelEach(len(src), workers, func(i int) error {
+		n, err := p.Unwrap(src[i])
+		if err != nil {
+			return err
+		}
+		dst[i] = n
+		return nil
+	})
+	return dst, err
+}
+
+// WrapStream converts integers received from in to strings using a pool of workers goroutines,
+// sending the results on the returned channel in the order they were received.
+// An integer outside the domain of the Permutation produces a WrapResult with a non-nil Err
+// and does not stop the others from being converted.
+// The returned channel is closed after in is closed and every result has been sent.
+// If workers is less than 1, runtime.GOMAXPROCS(0) workers are used.
+//
+// If ctx is cancelled, WrapStream stops reading from in, its goroutines exit,
+// and the returned channel is closed without sending the remaining results,
+// so a consumer which stops reading early should cancel ctx.
+func (p *Presents) WrapStream(ctx context.Context, in <-chan uint64, workers int) <-chan WrapResult {
+	type job struct {
+		index  int
+		n      uint64
+		result chan WrapResult
+	}
+	workers = workerCount(workers)
+	jobs := make(chan job)
+	pending := make(chan chan WrapResult, workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			for j := range jobs {
+				s, err := p.TryWrap(j.n)
+				j.result <- WrapResult{Index: j.index, S: s, Err: err}
+			}
+		}()
+	}
+	go func() {
+		defer close(pending)
+		defer close(jobs)
+		for i := 0; ; i++ {
+			var n uint64
+			var ok bool
+			select {
+			case n, ok = <-in:
+			case <-ctx.Done():
+				return
+			}
+			if !ok {
+				return
+			}
+			result := make(chan WrapResult, 1)
+			select {
+			case pending <- result:
+			case <-ctx.Done():
+				return
+			}
+			jobs <- job{index: i, n: n, result: result}
+		}
+	}()
+	return collect(ctx, pending)
+}
+
+// UnwrapStream converts strings received from in back to integers using a pool of workers goroutines,
+// sending the results on the returned channel in the order they were received.
+// A string that cannot be converted produces an UnwrapResult with a non-nil Err
+// and does not stop the others from being converted.
+// The returned channel is closed after in is closed and every result has been sent.
+// If workers is less than 1, runtime.GOMAXPROCS(0) workers are used.
+//
+// ctx is passed to UnwrapContext, and cancelling it stops the stream as for WrapStream.
+func (p *Presents) UnwrapStream(ctx context.Context, in <-chan string, workers int) <-chan UnwrapResult {
+	type job struct {
+		index  int
+		s      string
+		result chan UnwrapResult
+	}
+	workers = workerCount(workers)
+	jobs := make(chan job)
+	pending := make(chan chan UnwrapResult, workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			for j := range jobs {
+				n, err := p.UnwrapContext(ctx, j.s)
+				j.result <- UnwrapResult{Index: j.index, N: n, Err: err}
+			}
+		}()
+	}
+	go func() {
+		defer close(pending)
+		defer close(jobs)
+		for i := 0; ; i++ {
+			var s string
+			var ok bool
+			select {
+			case s, ok = <-in:
+			case <-ctx.Done():
+				return
+			}
+			if !ok {
+				return
+			}
+			result := make(chan UnwrapResult, 1)
+			select {
+			case pending <- result:
+			case <-ctx.Done():
+				return
+			}
+			jobs <- job{index: i, s: s, result: result}
+		}
+	}()
+	return collect(ctx, pending)
+}
+
+// collect sends the value from each channel received from pending on the returned channel, in order,
+// until pending is closed or ctx is cancelled.
+// Each channel in pending must be buffered, so that workers never block on it if collect returns early.
+func collect[T any](ctx context.Context, pending <-chan chan T) <-chan T {
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		for result := range pending {
+			var v T
+			select {
+			case v = <-result:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case out <- v:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// workerCount returns workers, or runtime.GOMAXPROCS(0) if workers is less than 1.
+func workerCount(workers int) int {
+	if workers < 1 {
+		return runtime.GOMAXPROCS(0)
+	}
+	return workers
+}
+
+// parallel splits [0, n) into up to workers contiguous chunks and calls f on each chunk in its own goroutine.
+// It returns once every call to f has returned.
+func parallel(n, workers int, f func(lo, hi int)) {
+	workers = workerCount(workers)
+	if workers > n {
+		workers = n
+	}
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		lo, hi := n*w/workers, n*(w+1)/workers
+		go func() {
+			defer wg.Done()
+			f(lo, hi)
+		}()
+	}
+	wg.Wait()
+}
+
+// parallelEach calls f for each index in [0, n) using up to workers goroutines, as for parallel.
+// It returns a BulkError containing an IndexError for each call which returned an error, sorted by index,
+// or nil if every call succeeded.
+func parallelEach(n, workers int, f func(i int) error) error {
+	var mu sync.Mutex
+	var errs BulkError
+	parallel(n, workers, func(lo, hi int) {
+		var chunkErrs BulkError
+		for i := lo; i < hi; i++ {
+			if err := f(i); err != nil {
+				chunkErrs = append(chunkErrs, &IndexError{Index: i, Err: err})
+			}
+		}
+		mu.Lock()
+		errs = append(errs, chunkErrs...)
+		mu.Unlock()
+	})
+	if errs == nil {
+		return nil
+	}
+	sort.Slice(errs, func(i, j int) bool {
+		return errs[i].Index < errs[j].Index
+	})
+	return errs
+}
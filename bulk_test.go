@@ -0,0 +1,253 @@
+package presents_test
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yi-jiayu/presents"
+)
+
+func TestPresents_WrapAll(t *testing.T) {
+	p, err := presents.New(make([]byte, 10), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := make([]uint64, 1000)
+	for i := range src {
+		src[i] = uint64(i)
+	}
+	for _, workers := range []int{0, 1, 3, 2000} {
+		dst, err := p.WrapAll(src, workers)
+		assert.NoError(t, err)
+		if assert.Len(t, dst, len(src)) {
+			for i, s := range dst {
+				assert.Equal(t, p.Wrap(src[i]), s)
+			}
+		}
+	}
+	dst, err := p.WrapAll(nil, 4)
+	assert.NoError(t, err)
+	assert.Empty(t, dst)
+}
+
+func TestPresents_WrapAll_domain(t *testing.T) {
+	perm, err := presents.NewFeistel([]byte("key"), 36)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := presents.Pipeline{Permutation: perm}.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := []uint64{1, 1 << 36, 2, 1<<64 - 1}
+	dst, err := p.WrapAll(src, 2)
+	assert.Equal(t, []string{p.Wrap(1), "", p.Wrap(2), ""}, dst)
+	if assert.IsType(t, presents.BulkError{}, err) {
+		errs := err.(presents.BulkError)
+		if assert.Len(t, errs, 2) {
+			assert.Equal(t, 1, errs[0].Index)
+			assert.Equal(t, 3, errs[1].Index)
+		}
+	}
+	assert.True(t, errors.Is(err, presents.ErrDomain))
+}
+
+func TestPresents_UnwrapAll(t *testing.T) {
+	p, err := presents.New(make([]byte, 10), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Run("ok", func(t *testing.T) {
+		src := []string{"90NyXHLckhA", p.Wrap(1), p.Wrap(2), p.Wrap(3)}
+		dst, err := p.UnwrapAll(src, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, []uint64{1213486160, 1, 2, 3}, dst)
+	})
+	t.Run("per-index errors", func(t *testing.T) {
+		src := []string{p.Wrap(1), "!", p.Wrap(2), "?", "#", p.Wrap(3)}
+		dst, err := p.UnwrapAll(src, 3)
+		assert.Equal(t, []uint64{1, 0, 2, 0, 0, 3}, dst)
+		if assert.IsType(t, presents.BulkError{}, err) {
+			errs := err.(presents.BulkError)
+			var indices []int
+			for _, e := range errs {
+				indices = append(indices, e.Index)
+				assert.Error(t, e.Err)
+			}
+			assert.Equal(t, []int{1, 3, 4}, indices)
+		}
+		assert.Contains(t, err.Error(), "3 errors")
+	})
+}
+
+func TestPresents_WrapStream(t *testing.T) {
+	p, err := presents.New(make([]byte, 10), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := make(chan uint64)
+	go func() {
+		for i := uint64(0); i < 500; i++ {
+			in <- i
+		}
+		close(in)
+	}()
+	var i int
+	for result := range p.WrapStream(context.Background(), in, 4) {
+		assert.Equal(t, i, result.Index)
+		assert.NoError(t, result.Err)
+		assert.Equal(t, p.Wrap(uint64(i)), result.S)
+		i++
+	}
+	assert.Equal(t, 500, i)
+}
+
+func TestPresents_WrapStream_domain(t *testing.T) {
+	perm, err := presents.NewFeistel([]byte("key"), 36)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := presents.Pipeline{Permutation: perm}.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := make(chan uint64)
+	go func() {
+		in <- 1
+		in <- 1 << 36
+		in <- 2
+		close(in)
+	}()
+	var results []presents.WrapResult
+	for result := range p.WrapStream(context.Background(), in, 2) {
+		results = append(results, result)
+	}
+	assert.Equal(t, []presents.WrapResult{
+		{Index: 0, S: p.Wrap(1)},
+		{Index: 1, Err: presents.ErrDomain},
+		{Index: 2, S: p.Wrap(2)},
+	}, results)
+}
+
+func TestPresents_UnwrapStream(t *testing.T) {
+	p, err := presents.New(make([]byte, 10), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := make(chan string)
+	go func() {
+		for i := uint64(0); i < 500; i++ {
+			if i%100 == 7 {
+				in <- "!"
+				continue
+			}
+			in <- p.Wrap(i)
+		}
+		close(in)
+	}()
+	var i int
+	for result := range p.UnwrapStream(context.Background(), in, 4) {
+		assert.Equal(t, i, result.Index)
+		if i%100 == 7 {
+			assert.Error(t, result.Err)
+		} else {
+			assert.NoError(t, result.Err)
+			assert.Equal(t, uint64(i), result.N)
+		}
+		i++
+	}
+	assert.Equal(t, 500, i)
+}
+
+func TestPresents_WrapStream_cancel(t *testing.T) {
+	p, err := presents.New(make([]byte, 10), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	in := make(chan uint64)
+	go func() {
+		// never closes in, so the stream only ends when ctx is cancelled
+		for i := uint64(0); ; i++ {
+			select {
+			case in <- i:
+			case <-time.After(time.Second):
+				return
+			}
+		}
+	}()
+	out := p.WrapStream(ctx, in, 4)
+	for i := uint64(0); i < 10; i++ {
+		assert.Equal(t, p.Wrap(i), (<-out).S)
+	}
+	cancel()
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case _, ok := <-out:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("WrapStream did not stop after ctx was cancelled")
+		}
+	}
+}
+
+func TestPresents_UnwrapStream_cancel(t *testing.T) {
+	p, err := presents.New(make([]byte, 10), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	in := make(chan string)
+	select {
+	case _, ok := <-p.UnwrapStream(ctx, in, 4):
+		assert.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("UnwrapStream did not stop after ctx was cancelled")
+	}
+}
+
+// Presents instances are read-only after construction and can be shared between goroutines.
+func TestPresents_concurrentUse(t *testing.T) {
+	p, err := presents.New(make([]byte, 10), &presents.Options{Shuffle: true, Seed: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := make([]uint64, 64)
+	r := rand.New(rand.NewSource(1))
+	for i := range src {
+		src[i] = r.Uint64()
+	}
+	expected := make([]string, len(src))
+	for i, n := range src {
+		expected[i] = p.Wrap(n)
+	}
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i, n := range src {
+				s := p.Wrap(n)
+				assert.Equal(t, expected[i], s)
+				m, err := p.Unwrap(s)
+				assert.NoError(t, err)
+				assert.Equal(t, n, m)
+			}
+			dst := make([]string, len(src))
+			p.WrapBatch(dst, src)
+			assert.Equal(t, expected, dst)
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,139 @@
+package presents
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sync"
+)
+
+// CanaryEvent describes a canary token which has been unwrapped.
+type CanaryEvent struct {
+	// Namespace is the namespace the canary was added to.
+	Namespace string
+	// ID is the canary ID.
+	ID uint64
+	// Token is the string which was unwrapped.
+	Token string
+}
+
+// CanaryHandler is called when a canary token is unwrapped.
+// ctx is the context passed to Presents.UnwrapContext, or context.Background for Presents.Unwrap.
+// It is called synchronously by Unwrap, so it should hand slow work such as sending alerts to another goroutine.
+type CanaryHandler func(ctx context.Context, event CanaryEvent)
+
+// Canaries is a set of canary IDs, such as the IDs of fake records planted in exported data.
+// When it is passed to New in Options.Canaries, unwrapping the token of any of its IDs calls its handler,
+// and Unwrap still returns the ID so that whoever presented the token is not alerted.
+//
+// Each ID belongs to a namespace, which is reported to the handler and can be used to record where it was planted.
+// A Canaries is safe for concurrent use by multiple goroutines,
+// and IDs may be added and removed while it is in use.
+type Canaries struct {
+	handler CanaryHandler
+
+	mu  sync.RWMutex
+	ids map[uint64]string
+}
+
+// NewCanaries returns an empty set of canary IDs which calls handler when one of them is unwrapped.
+func NewCanaries(handler CanaryHandler) *Canaries {
+	return &Canaries{
+		handler: handler,
+		ids:     make(map[uint64]string),
+	}
+}
+
+// Add adds ids to the namespace. IDs which were already canaries are moved to the namespace.
+func (c *Canaries) Add(namespace string, ids ...uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, id := range ids {
+		c.ids[id] = namespace
+	}
+}
+
+// Remove removes ids, whichever namespace they belong to.
+func (c *Canaries) Remove(ids ...uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, id := range ids {
+		delete(c.ids, id)
+	}
+}
+
+// Lookup returns the namespace of id, and reports whether it is a canary.
+func (c *Canaries) Lookup(id uint64) (namespace string, ok bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	namespace, ok = c.ids[id]
+	return
+}
+
+// Mint chooses a random ID between min and max inclusive which is not already a canary, and adds it to the namespace.
+// The range should not overlap with real IDs, but should be close enough to them that the canary looks real.
+func (c *Canaries) Mint(namespace string, min, max uint64) (uint64, error) {
+	if min > max {
+		return 0, errors.New("presents: Canaries.Mint: min is greater than max")
+	}
+	span := max - min + 1 // zero when the range covers every uint64
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for attempts := 0; attempts < 100; attempts++ {
+		id, err := c.uniform(span)
+		if err != nil {
+			return 0, err
+		}
+		id += min
+		if _, ok := c.ids[id]; !ok {
+			c.ids[id] = namespace
+			return id, nil
+		}
+	}
+	return 0, errors.New("presents: Canaries.Mint: range is full")
+}
+
+// uniform returns a random integer less than n, or any random integer if n is zero.
+func (c *Canaries) uniform(n uint64) (uint64, error) {
+	// reject values from the incomplete copy of the range at the top
+	limit := ^uint64(0)
+	if n != 0 {
+		limit -= (limit%n + 1) % n
+	}
+	b := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(rand.Reader, b); err != nil {
+			return 0, err
+		}
+		x := binary.BigEndian.Uint64(b)
+		if x <= limit {
+			if n == 0 {
+				return x, nil
+			}
+			return x % n, nil
+		}
+	}
+}
+
+// notify calls the handler if id is a canary.
+func (c *Canaries) notify(ctx context.Context, id uint64, token string) {
+	namespace, ok := c.Lookup(id)
+	if ok && c.handler != nil {
+		c.handler(ctx, CanaryEvent{Namespace: namespace, ID: id, Token: token})
+	}
+}
+
+// MintCanary mints a canary ID in the namespace using the Canaries from Options.Canaries, and returns its token.
+// It returns an error if the Presents has no Canaries.
+func (p *Presents) MintCanary(namespace string, min, max uint64) (string, error) {
+	if p.canaries == nil {
+		return "", errors.New("presents: MintCanary: no Canaries in Options")
+	}
+	id, err := p.canaries.Mint(namespace, min, max)
+	if err != nil {
+		return "", err
+	}
+	return p.Wrap(id), nil
+}
@@ -0,0 +1,104 @@
+package presents_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yi-jiayu/presents"
+)
+
+type contextKey string
+
+func TestCanaries(t *testing.T) {
+	var events []presents.CanaryEvent
+	var users []interface{}
+	canaries := presents.NewCanaries(func(ctx context.Context, event presents.CanaryEvent) {
+		events = append(events, event)
+		users = append(users, ctx.Value(contextKey("user")))
+	})
+	canaries.Add("spreadsheet", 1000, 1001)
+	p, err := presents.New(make([]byte, 10), &presents.Options{Canaries: canaries, MaxID: 500})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := p.Unwrap(p.Wrap(1))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), n)
+	assert.Empty(t, events)
+
+	ctx := context.WithValue(context.Background(), contextKey("user"), "mallory")
+	token := p.Wrap(1000)
+	_, err = p.UnwrapContext(ctx, token)
+	assert.Error(t, err, "canaries are still subject to MaxID")
+	assert.Equal(t, []presents.CanaryEvent{{Namespace: "spreadsheet", ID: 1000, Token: token}}, events)
+	assert.Equal(t, []interface{}{"mallory"}, users)
+
+	canaries.Remove(1000)
+	_, ok := canaries.Lookup(1000)
+	assert.False(t, ok)
+	p.Unwrap(token)
+	assert.Len(t, events, 1)
+
+	canaries.Add("export", 1001)
+	namespace, ok := canaries.Lookup(1001)
+	assert.True(t, ok)
+	assert.Equal(t, "export", namespace)
+}
+
+func TestCanaries_Mint(t *testing.T) {
+	canaries := presents.NewCanaries(nil)
+	seen := make(map[uint64]bool)
+	for i := 0; i < 3; i++ {
+		id, err := canaries.Mint("test", 10, 12)
+		assert.NoError(t, err)
+		assert.True(t, 10 <= id && id <= 12)
+		assert.False(t, seen[id])
+		seen[id] = true
+	}
+	_, err := canaries.Mint("test", 10, 12)
+	assert.EqualError(t, err, "presents: Canaries.Mint: range is full")
+	_, err = canaries.Mint("test", 12, 10)
+	assert.Error(t, err)
+	_, err = canaries.Mint("test", 0, 1<<64-1)
+	assert.NoError(t, err)
+}
+
+func TestPresents_MintCanary(t *testing.T) {
+	var mu sync.Mutex
+	var events []presents.CanaryEvent
+	canaries := presents.NewCanaries(func(ctx context.Context, event presents.CanaryEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+	})
+	p, err := presents.New(make([]byte, 10), &presents.Options{Canaries: canaries})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			token, err := p.MintCanary("concurrent", 1<<40, 1<<41)
+			assert.NoError(t, err)
+			n, err := p.Unwrap(token)
+			assert.NoError(t, err)
+			namespace, ok := canaries.Lookup(n)
+			assert.True(t, ok)
+			assert.Equal(t, "concurrent", namespace)
+		}()
+	}
+	wg.Wait()
+	assert.Len(t, events, 8)
+
+	p, err = presents.New(make([]byte, 10), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = p.MintCanary("test", 0, 100)
+	assert.Error(t, err)
+}
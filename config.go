@@ -0,0 +1,334 @@
+package presents
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Config is a serialisable description of a codec, for sharing between services in configuration files.
+// It contains everything which affects the strings a codec produces except the key,
+// which should be distributed separately as a secret.
+//
+// A Config can be encoded as JSON, and as text of the form
+//
+//	present-128?alphabet=0123456789abcdef&padding=true&namespace=users
+//
+// which is convenient for environment variables and flags, and which YAML libraries use for encoding.TextMarshaler.
+// Both forms reject unknown fields, so that a misspelt setting is an error rather than a different codec.
+type Config struct {
+	// Cipher is the name of a cipher, as accepted by NewFromSpec.
+	Cipher string
+	// Alphabet is the alphabet, or the empty string for DefaultAlphabet.
+	Alphabet string
+	// Shuffle, Seed and KeyedShuffle are as for Options.
+	Shuffle      bool
+	Seed         int64
+	KeyedShuffle bool
+	// Padding, GroupSize and Separator are as for Options.
+	Padding   bool
+	GroupSize int
+	Separator rune
+	// Blocklist is as for Options.
+	Blocklist []string
+	// Namespace is as for WithNamespace, or the empty string for none.
+	Namespace string
+}
+
+// Options returns the options described by c, for use with New and the other constructors.
+func (c Config) Options() []Option {
+	var options []Option
+	if c.Alphabet != "" {
+		options = append(options, WithAlphabet(c.Alphabet))
+	}
+	if c.Shuffle {
+		options = append(options, WithShuffle(c.Seed))
+	}
+	if c.KeyedShuffle {
+		options = append(options, WithKeyedShuffle())
+	}
+	if c.Padding {
+		options = append(options, WithPadding())
+	}
+	if c.GroupSize != 0 {
+		options = append(options, WithGrouping(c.GroupSize, c.Separator))
+	}
+	if len(c.Blocklist) > 0 {
+		options = append(options, WithBlocklist(c.Blocklist...))
+	}
+	if c.Namespace != "" {
+		options = append(options, WithNamespace(c.Namespace))
+	}
+	return options
+}
+
+// New creates a new Presents struct using the cipher and options described by c.
+// Further options, such as WithMaxID or WithObserver, may be added.
+func (c Config) New(key []byte, options ...Option) (*Presents, error) {
+	return NewFromSpec(c.Cipher, key, append(c.Options(), options...)...)
+}
+
+// validate checks that c describes a valid combination of options.
+func (c Config) validate() error {
+	if c.Cipher == "" {
+		return errors.New("presents: Config: cipher is empty")
+	}
+	if c.Seed != 0 && !c.Shuffle {
+		return errors.New("presents: Config: seed is set but shuffle is not")
+	}
+	if c.Separator != 0 && c.GroupSize == 0 {
+		return errors.New("presents: Config: separator is set but groupSize is not")
+	}
+	_, err := newConfig(c.Options())
+	return err
+}
+
+// effective returns c with defaults filled in, so that equivalent Configs are equal.
+func (c Config) effective() Config {
+	if c.Alphabet == "" {
+		c.Alphabet = string(DefaultAlphabet)
+	}
+	if !c.Shuffle {
+		c.Seed = 0
+	}
+	if c.GroupSize > 0 && c.Separator == 0 {
+		c.Separator = DefaultSeparator
+	}
+	words := newBlocklist(c.Blocklist)
+	sort.Strings(words)
+	c.Blocklist = nil
+	for i, w := range words {
+		if i == 0 || w != words[i-1] {
+			c.Blocklist = append(c.Blocklist, w)
+		}
+	}
+	return c
+}
+
+// Fingerprint returns a hexadecimal digest of the effective configuration described by c
+// and a commitment to key, such as 3f2a...
+// Services which share a codec can log or compare their fingerprints at startup
+// to make sure that they agree on every setting and on the key.
+// Configs which differ only in defaults, such as an empty Alphabet and DefaultAlphabet, have the same fingerprint.
+//
+// The commitment is made by encrypting fixed blocks with the codec, so computing it is no harder than wrapping an ID.
+// Anyone who sees a fingerprint can therefore test guesses of the key offline;
+// this is infeasible for random keys, but a key derived from a weak passphrase is only protected
+// by the cost of the derivation, such as the PBKDF2 iterations of NewFromPassphrase.
+//
+// Fingerprint returns an error if c and key cannot be used to create a codec.
+func (c Config) Fingerprint(key []byte) (string, error) {
+	p, err := c.New(key)
+	if err != nil {
+		return "", err
+	}
+	text, err := c.effective().MarshalText()
+	if err != nil {
+		return "", err
+	}
+	var commitment [8 * len(fingerprintBlocks)]byte
+	for i, b := range fingerprintBlocks {
+		binary.BigEndian.PutUint64(commitment[8*i:], p.encrypt(b))
+	}
+
+	h := sha256.New()
+	h.Write([]byte("presents config fingerprint v1\x00"))
+	h.Write(commitment[:])
+	h.Write(text)
+	return hex.EncodeToString(h.Sum(nil)[:16]), nil
+}
+
+// fingerprintBlocks are encrypted by Fingerprint to commit to the key.
+var fingerprintBlocks = [2]uint64{0x7072657365667030, 0x7072657365667031}
+
+// jsonConfig is the JSON representation of a Config.
+type jsonConfig struct {
+	Cipher       string   `json:"cipher"`
+	Alphabet     string   `json:"alphabet,omitempty"`
+	Shuffle      bool     `json:"shuffle,omitempty"`
+	Seed         *int64   `json:"seed,omitempty,string"`
+	KeyedShuffle bool     `json:"keyedShuffle,omitempty"`
+	Padding      bool     `json:"padding,omitempty"`
+	GroupSize    int      `json:"groupSize,omitempty"`
+	Separator    string   `json:"separator,omitempty"`
+	Blocklist    []string `json:"blocklist,omitempty"`
+	Namespace    string   `json:"namespace,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler.
+// Seed is encoded as a string, since it may not fit in the numbers of other languages,
+// and is always included when Shuffle is true.
+func (c Config) MarshalJSON() ([]byte, error) {
+	j := jsonConfig{
+		Cipher:       c.Cipher,
+		Alphabet:     c.Alphabet,
+		Shuffle:      c.Shuffle,
+		KeyedShuffle: c.KeyedShuffle,
+		Padding:      c.Padding,
+		GroupSize:    c.GroupSize,
+		Blocklist:    c.Blocklist,
+		Namespace:    c.Namespace,
+	}
+	if c.Shuffle || c.Seed != 0 {
+		seed := c.Seed
+		j.Seed = &seed
+	}
+	if c.Separator != 0 {
+		j.Separator = string(c.Separator)
+	}
+	return json.Marshal(j)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+// It returns an error if data contains unknown fields or describes an invalid combination of options,
+// including shuffle without an explicit seed, so that a forgotten seed is not silently taken to be 0.
+func (c *Config) UnmarshalJSON(data []byte) error {
+	var j jsonConfig
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.DisallowUnknownFields()
+	if err := d.Decode(&j); err != nil {
+		return fmt.Errorf("presents: Config: %w", err)
+	}
+	sep, err := parseSeparator(j.Separator)
+	if err != nil {
+		return fmt.Errorf("presents: Config: invalid separator: %w", err)
+	}
+	if j.Shuffle && j.Seed == nil {
+		return errSeedMissing
+	}
+	cfg := Config{
+		Cipher:       j.Cipher,
+		Alphabet:     j.Alphabet,
+		Shuffle:      j.Shuffle,
+		KeyedShuffle: j.KeyedShuffle,
+		Padding:      j.Padding,
+		GroupSize:    j.GroupSize,
+		Separator:    sep,
+		Blocklist:    j.Blocklist,
+		Namespace:    j.Namespace,
+	}
+	if j.Seed != nil {
+		cfg.Seed = *j.Seed
+	}
+	if err := cfg.validate(); err != nil {
+		return err
+	}
+	*c = cfg
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler.
+// The fields after the cipher name are sorted, so equal Configs always have the same text.
+func (c Config) MarshalText() ([]byte, error) {
+	if c.Cipher == "" || strings.ContainsRune(c.Cipher, '?') {
+		return nil, fmt.Errorf("presents: Config: invalid cipher %q", c.Cipher)
+	}
+	v := make(url.Values)
+	if c.Alphabet != "" {
+		v.Set("alphabet", c.Alphabet)
+	}
+	if c.Shuffle {
+		v.Set("shuffle", "true")
+	}
+	if c.Shuffle || c.Seed != 0 {
+		v.Set("seed", strconv.FormatInt(c.Seed, 10))
+	}
+	if c.KeyedShuffle {
+		v.Set("keyedShuffle", "true")
+	}
+	if c.Padding {
+		v.Set("padding", "true")
+	}
+	if c.GroupSize != 0 {
+		v.Set("groupSize", strconv.Itoa(c.GroupSize))
+	}
+	if c.Separator != 0 {
+		v.Set("separator", string(c.Separator))
+	}
+	for _, w := range c.Blocklist {
+		v.Add("blocklist", w)
+	}
+	if c.Namespace != "" {
+		v.Set("namespace", c.Namespace)
+	}
+	text := c.Cipher
+	if len(v) > 0 {
+		text += "?" + v.Encode()
+	}
+	return []byte(text), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+// It returns an error if text contains unknown or repeated fields, or describes an invalid combination of options,
+// including shuffle without an explicit seed, as for UnmarshalJSON.
+func (c *Config) UnmarshalText(text []byte) error {
+	cipherName, query, _ := strings.Cut(string(text), "?")
+	v, err := url.ParseQuery(query)
+	if err != nil {
+		return fmt.Errorf("presents: Config: %w", err)
+	}
+	cfg := Config{Cipher: cipherName}
+	for key, values := range v {
+		if key != "blocklist" && len(values) > 1 {
+			return fmt.Errorf("presents: Config: %s given more than once", key)
+		}
+		value := values[0]
+		switch key {
+		case "alphabet":
+			cfg.Alphabet = value
+		case "shuffle":
+			cfg.Shuffle, err = strconv.ParseBool(value)
+		case "seed":
+			cfg.Seed, err = strconv.ParseInt(value, 10, 64)
+		case "keyedShuffle":
+			cfg.KeyedShuffle, err = strconv.ParseBool(value)
+		case "padding":
+			cfg.Padding, err = strconv.ParseBool(value)
+		case "groupSize":
+			cfg.GroupSize, err = strconv.Atoi(value)
+		case "separator":
+			cfg.Separator, err = parseSeparator(value)
+		case "blocklist":
+			cfg.Blocklist = values
+		case "namespace":
+			cfg.Namespace = value
+		default:
+			return fmt.Errorf("presents: Config: unknown field %q", key)
+		}
+		if err != nil {
+			return fmt.Errorf("presents: Config: invalid %s: %w", key, err)
+		}
+	}
+	if _, ok := v["seed"]; cfg.Shuffle && !ok {
+		return errSeedMissing
+	}
+	if err := cfg.validate(); err != nil {
+		return err
+	}
+	*c = cfg
+	return nil
+}
+
+// errSeedMissing is returned when a serialised Config enables shuffle without giving a seed.
+var errSeedMissing = errors.New("presents: Config: shuffle is set but seed is not")
+
+// parseSeparator converts a string containing a single character, or the empty string, to a separator.
+func parseSeparator(s string) (rune, error) {
+	if s == "" {
+		return 0, nil
+	}
+	r, size := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError || size != len(s) {
+		return 0, fmt.Errorf("%q is not a single character", s)
+	}
+	return r, nil
+}
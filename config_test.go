@@ -0,0 +1,212 @@
+package presents_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yi-jiayu/presents"
+)
+
+var testConfig = presents.Config{
+	Cipher:    "present-128",
+	Alphabet:  "0123456789ABCDEFGHJKMNPQRSTVWXYZ",
+	Shuffle:   true,
+	Seed:      1 << 60,
+	Padding:   true,
+	GroupSize: 4,
+	Separator: ' ',
+	Blocklist: []string{"bad"},
+	Namespace: "users",
+}
+
+func TestConfig_JSON(t *testing.T) {
+	b, err := json.Marshal(testConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.JSONEq(t, `{
+		"cipher": "present-128",
+		"alphabet": "0123456789ABCDEFGHJKMNPQRSTVWXYZ",
+		"shuffle": true,
+		"seed": "1152921504606846976",
+		"padding": true,
+		"groupSize": 4,
+		"separator": " ",
+		"blocklist": ["bad"],
+		"namespace": "users"
+	}`, string(b))
+
+	var c presents.Config
+	if err := json.Unmarshal(b, &c); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, testConfig, c)
+}
+
+func TestConfig_Text(t *testing.T) {
+	b, err := testConfig.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "present-128?alphabet=0123456789ABCDEFGHJKMNPQRSTVWXYZ&blocklist=bad&groupSize=4&namespace=users"+
+		"&padding=true&seed=1152921504606846976&separator=+&shuffle=true", string(b))
+
+	var c presents.Config
+	if err := c.UnmarshalText(b); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, testConfig, c)
+
+	b, err = presents.Config{Cipher: "speck64/96"}.MarshalText()
+	assert.NoError(t, err)
+	assert.Equal(t, "speck64/96", string(b))
+}
+
+func TestConfig_zeroSeed(t *testing.T) {
+	config := presents.Config{Cipher: "prince", Shuffle: true}
+	b, err := json.Marshal(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.JSONEq(t, `{"cipher": "prince", "shuffle": true, "seed": "0"}`, string(b))
+	var c presents.Config
+	if err := json.Unmarshal(b, &c); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, config, c)
+
+	b, err = config.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "prince?seed=0&shuffle=true", string(b))
+	c = presents.Config{}
+	if err := c.UnmarshalText(b); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, config, c)
+}
+
+func TestConfig_unmarshalErrors(t *testing.T) {
+	testCases := []struct {
+		name string
+		json string
+		text string
+		err  string
+	}{
+		{"unknown field", `{"cipher": "prince", "paddding": true}`, "prince?paddding=true", ""},
+		{"no cipher", `{"padding": true}`, "?padding=true", "presents: Config: cipher is empty"},
+		{"seed without shuffle", `{"cipher": "prince", "seed": "1"}`, "prince?seed=1", "presents: Config: seed is set but shuffle is not"},
+		{"shuffle without seed", `{"cipher": "prince", "shuffle": true}`, "prince?shuffle=true", "presents: Config: shuffle is set but seed is not"},
+		{
+			"separator without group size",
+			`{"cipher": "prince", "separator": "-"}`,
+			"prince?separator=-",
+			"presents: Config: separator is set but groupSize is not",
+		},
+		{
+			"conflicting shuffles",
+			`{"cipher": "prince", "shuffle": true, "seed": "1", "keyedShuffle": true}`,
+			"prince?shuffle=true&seed=1&keyedShuffle=true",
+			"presents: WithShuffle and WithKeyedShuffle cannot be combined",
+		},
+		{
+			"negative group size",
+			`{"cipher": "prince", "groupSize": -1}`,
+			"prince?groupSize=-1",
+			"presents: WithGrouping: size must be positive, not -1",
+		},
+		{
+			"long separator",
+			`{"cipher": "prince", "groupSize": 4, "separator": "--"}`,
+			"prince?groupSize=4&separator=--",
+			`presents: Config: invalid separator: "--" is not a single character`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var c presents.Config
+			err := json.Unmarshal([]byte(tc.json), &c)
+			if tc.err == "" {
+				assert.Error(t, err)
+			} else {
+				assert.EqualError(t, err, tc.err)
+			}
+			err = c.UnmarshalText([]byte(tc.text))
+			if tc.err == "" {
+				assert.Error(t, err)
+			} else {
+				assert.EqualError(t, err, tc.err)
+			}
+		})
+	}
+
+	var c presents.Config
+	assert.EqualError(t, c.UnmarshalText([]byte("prince?padding=true&padding=false")), "presents: Config: padding given more than once")
+	assert.EqualError(t, c.UnmarshalText([]byte("prince?padding=yes")),
+		`presents: Config: invalid padding: strconv.ParseBool: parsing "yes": invalid syntax`)
+}
+
+func TestConfig_New(t *testing.T) {
+	key := make([]byte, 16)
+	p, err := testConfig.New(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, err := presents.New(key,
+		presents.WithAlphabet(testConfig.Alphabet),
+		presents.WithShuffle(testConfig.Seed),
+		presents.WithPadding(),
+		presents.WithGrouping(4, ' '),
+		presents.WithBlocklist("bad"),
+		presents.WithNamespace("users"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, q.Wrap(1213486160), p.Wrap(1213486160))
+
+	_, err = testConfig.New(key, presents.WithPadding())
+	assert.EqualError(t, err, "presents: WithPadding given more than once")
+}
+
+func TestConfig_Fingerprint(t *testing.T) {
+	key := make([]byte, 16)
+	fp, err := testConfig.Fingerprint(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, fp, 32)
+
+	// defaults and equivalent blocklists do not change the fingerprint
+	a, err := presents.Config{Cipher: "present-128", GroupSize: 4, Blocklist: []string{"Foo", "bar", "foo"}}.Fingerprint(key)
+	assert.NoError(t, err)
+	b, err := presents.Config{
+		Cipher: "present-128", Alphabet: string(presents.DefaultAlphabet), GroupSize: 4, Separator: '-', Blocklist: []string{"bar", "foo"},
+	}.Fingerprint(key)
+	assert.NoError(t, err)
+	assert.Equal(t, a, b)
+
+	otherKey := make([]byte, 16)
+	otherKey[0] = 1
+	different := []struct {
+		config presents.Config
+		key    []byte
+	}{
+		{testConfig, otherKey},
+		{presents.Config{Cipher: "speck64/128", Alphabet: testConfig.Alphabet, Shuffle: true, Seed: testConfig.Seed,
+			Padding: true, GroupSize: 4, Separator: ' ', Blocklist: []string{"bad"}, Namespace: "users"}, key},
+		{presents.Config{Cipher: "present-128", Alphabet: testConfig.Alphabet, Shuffle: true, Seed: testConfig.Seed,
+			Padding: true, GroupSize: 4, Separator: ' ', Blocklist: []string{"bad"}, Namespace: "orders"}, key},
+		{presents.Config{Cipher: "present-128", Alphabet: testConfig.Alphabet, Shuffle: true, Seed: 1,
+			Padding: true, GroupSize: 4, Separator: ' ', Blocklist: []string{"bad"}, Namespace: "users"}, key},
+	}
+	for _, d := range different {
+		other, err := d.config.Fingerprint(d.key)
+		assert.NoError(t, err)
+		assert.NotEqual(t, fp, other)
+	}
+
+	_, err = testConfig.Fingerprint(make([]byte, 10))
+	assert.Error(t, err)
+}
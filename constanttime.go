@@ -0,0 +1,116 @@
+package presents
+
+import (
+	"crypto/subtle"
+	"encoding/binary"
+	"math/bits"
+	"unicode/utf8"
+)
+
+// constantTimePresent implements PRESENT for single blocks without secret-dependent table lookups or branches.
+// The S-box layer evaluates the same boolean formulas as bitslicedPresent on the four bit planes of the state,
+// and the permutation layer moves every bit regardless of its value.
+type constantTimePresent struct {
+	roundKeys [presentRounds + 1]uint64
+}
+
+// newConstantTimePresent expands an 80-bit or 128-bit PRESENT key.
+// It returns nil if the key has any other length.
+func newConstantTimePresent(key []byte) *constantTimePresent {
+	b := newBitslicedPresent(key)
+	if b == nil {
+		return nil
+	}
+	return &constantTimePresent{roundKeys: b.roundKeys}
+}
+
+func (c *constantTimePresent) BlockSize() int {
+	return 8
+}
+
+func (c *constantTimePresent) Encrypt(dst, src []byte) {
+	if len(src) < 8 {
+		panic("presents: present: input not full block")
+	}
+	if len(dst) < 8 {
+		panic("presents: present: output not full block")
+	}
+	s := binary.BigEndian.Uint64(src)
+	for r := 0; r < presentRounds; r++ {
+		s ^= c.roundKeys[r]
+		s = sBoxWord(s, sBoxSliced)
+		var t uint64
+		for j := uint(0); j < 63; j++ {
+			t |= (s >> j & 1) << (j * 16 % 63)
+		}
+		s = t | s&(1<<63)
+	}
+	s ^= c.roundKeys[presentRounds]
+	binary.BigEndian.PutUint64(dst, s)
+}
+
+func (c *constantTimePresent) Decrypt(dst, src []byte) {
+	if len(src) < 8 {
+		panic("presents: present: input not full block")
+	}
+	if len(dst) < 8 {
+		panic("presents: present: output not full block")
+	}
+	s := binary.BigEndian.Uint64(src)
+	s ^= c.roundKeys[presentRounds]
+	for r := presentRounds - 1; r >= 0; r-- {
+		var t uint64
+		for j := uint(0); j < 63; j++ {
+			t |= (s >> (j * 16 % 63) & 1) << j
+		}
+		s = t | s&(1<<63)
+		s = sBoxWord(s, sBoxInvSliced)
+		s ^= c.roundKeys[r]
+	}
+	binary.BigEndian.PutUint64(dst, s)
+}
+
+// decodeConstantTime is equivalent to Decode, but its running time depends only on the lengths of s and a.
+// Every character of s is compared against every character of the alphabet using masks instead of branches,
+// and errors are only reported once the whole string has been scanned.
+func (a Alphabet) decodeConstantTime(s string) (uint64, error) {
+	if s == "" {
+		return 0, ErrLength
+	}
+	b := uint64(len(a))
+	mag := uint64(1)
+	var n, overflow, magOverflow, invalid uint64
+	invalidPos := 0
+	for i := 0; i < len(s); i++ {
+		var x, valid uint64
+		for j := 0; j < len(a); j++ {
+			eq := uint64(subtle.ConstantTimeByteEq(s[i], a[j]))
+			x |= uint64(j) & -eq
+			valid |= eq
+		}
+		// remember the position of the first invalid character
+		first := (valid ^ 1) & (invalid ^ 1)
+		invalidPos = subtle.ConstantTimeSelect(int(first), i, invalidPos)
+		invalid |= valid ^ 1
+
+		hi, lo := bits.Mul64(x, mag)
+		var carry uint64
+		n, carry = bits.Add64(n, lo, 0)
+		overflow |= nonZero(hi) | carry | magOverflow&nonZero(x)
+		hi, mag = bits.Mul64(mag, b)
+		magOverflow |= nonZero(hi)
+	}
+	if invalid != 0 {
+		c, _ := utf8.DecodeRuneInString(s[invalidPos:])
+		return 0, &InvalidCharError{Pos: invalidPos, Char: c}
+	}
+	if overflow != 0 {
+		return 0, ErrOverflow
+	}
+	return n, nil
+}
+
+// nonZero returns 1 if x is not zero and 0 otherwise, without branching.
+func nonZero(x uint64) uint64 {
+	return (x | -x) >> 63
+}
@@ -0,0 +1,130 @@
+package presents
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yi-jiayu/PRESENT.go"
+)
+
+func TestSBoxInvSliced(t *testing.T) {
+	for x := uint64(0); x < 16; x++ {
+		y0, y1, y2, y3 := sBoxInvSliced(-(x & 1), -(x >> 1 & 1), -(x >> 2 & 1), -(x >> 3 & 1))
+		y := y0&1 | y1&1<<1 | y2&1<<2 | y3&1<<3
+		assert.Equal(t, x, presentSBox[y], "S⁻¹(%x)", x)
+	}
+}
+
+func TestSBoxWord(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		s := r.Uint64()
+		var expected uint64
+		for j := uint(0); j < 64; j += 4 {
+			expected |= presentSBox[s>>j&0xf] << j
+		}
+		assert.Equal(t, expected, sBoxWord(s, sBoxSliced))
+		assert.Equal(t, s, sBoxWord(expected, sBoxInvSliced))
+	}
+}
+
+func TestConstantTimePresent(t *testing.T) {
+	for _, keySize := range []int{10, 16} {
+		key := make([]byte, keySize)
+		r := rand.New(rand.NewSource(int64(keySize)))
+		r.Read(key)
+		c, err := present.NewCipher(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ct := newConstantTimePresent(key)
+		src, expected, actual := make([]byte, 8), make([]byte, 8), make([]byte, 8)
+		for i := 0; i < 100; i++ {
+			binary.BigEndian.PutUint64(src, r.Uint64())
+			c.Encrypt(expected, src)
+			ct.Encrypt(actual, src)
+			assert.Equal(t, expected, actual, "%d-bit key", keySize*8)
+			ct.Decrypt(actual, actual)
+			assert.Equal(t, src, actual, "%d-bit key", keySize*8)
+		}
+	}
+	assert.Nil(t, newConstantTimePresent(make([]byte, 12)))
+}
+
+func TestAlphabet_decodeConstantTime(t *testing.T) {
+	inputs := []string{"", "0", "a", "HyR4yw5GiwJ", "zzzzzzzzzzz", "zzzzzzzzzzzz", "v8QrKbgkrIp", "v8QrKbgkrIq",
+		"v8QrKbgkrIp0000", "abc-def", "ab€", "-", "00000000000000000001"}
+	for _, s := range inputs {
+		n, err := DefaultAlphabet.Decode(s)
+		ctN, ctErr := DefaultAlphabet.decodeConstantTime(s)
+		assert.Equal(t, n, ctN, "%q", s)
+		assert.Equal(t, err, ctErr, "%q", s)
+	}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		n := r.Uint64()
+		actual, err := DefaultAlphabet.decodeConstantTime(DefaultAlphabet.Encode(n))
+		assert.NoError(t, err)
+		assert.Equal(t, n, actual)
+	}
+}
+
+// medianDuration returns the median time taken by f over a number of runs.
+func medianDuration(f func()) time.Duration {
+	const runs = 201
+	durations := make([]time.Duration, runs)
+	for i := range durations {
+		start := time.Now()
+		for j := 0; j < 100; j++ {
+			f()
+		}
+		durations[i] = time.Since(start)
+	}
+	sort.Slice(durations, func(i, j int) bool {
+		return durations[i] < durations[j]
+	})
+	return durations[runs/2]
+}
+
+func TestAlphabet_decodeConstantTime_timing(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping timing test in short mode")
+	}
+	valid := DefaultAlphabet.Encode(1<<63 + 12345)
+	invalid := "-" + valid[1:]
+	validTime := medianDuration(func() { DefaultAlphabet.decodeConstantTime(valid) })
+	invalidTime := medianDuration(func() { DefaultAlphabet.decodeConstantTime(invalid) })
+	ratio := float64(validTime) / float64(invalidTime)
+	t.Logf("valid: %v, invalid: %v, ratio: %.2f", validTime, invalidTime, ratio)
+	// the generous bound keeps noisy machines from failing the test,
+	// while still catching an early return, which makes invalid input several times faster
+	assert.True(t, 0.5 < ratio && ratio < 2, "ratio %.2f", ratio)
+}
+
+func TestNew_constantTime(t *testing.T) {
+	for _, keySize := range []int{10, 16} {
+		key := make([]byte, keySize)
+		p, err := New(key, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ct, err := New(key, &Options{ConstantTime: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.IsType(t, cipherPermutation{block: &constantTimePresent{}}, ct.permutation)
+		for _, n := range []uint64{0, 1, 1213486160, 1<<64 - 1} {
+			s := p.Wrap(n)
+			assert.Equal(t, s, ct.Wrap(n))
+			actual, err := ct.Unwrap(s)
+			assert.NoError(t, err)
+			assert.Equal(t, n, actual)
+		}
+		_, err = ct.Unwrap("abc-def")
+		assert.Equal(t, &InvalidCharError{Pos: 3, Char: '-'}, err)
+	}
+}
@@ -1,6 +1,10 @@
 package presents_test
 
 import (
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 
@@ -30,6 +34,60 @@ func Example() {
 	// 1213486160
 }
 
+// This example shows how to customise a Presents with options.
+// The same ID wraps to unrelated strings in different namespaces.
+func ExampleNew_options() {
+	key := make([]byte, 10)
+	users, err := presents.New(key,
+		presents.WithAlphabet("0123456789ABCDEFGHJKMNPQRSTVWXYZ"),
+		presents.WithPadding(),
+		presents.WithGrouping(4, '-'),
+		presents.WithNamespace("users"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	orders, err := presents.New(key,
+		presents.WithAlphabet("0123456789ABCDEFGHJKMNPQRSTVWXYZ"),
+		presents.WithPadding(),
+		presents.WithGrouping(4, '-'),
+		presents.WithNamespace("orders"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(users.Wrap(42))
+	fmt.Println(orders.Wrap(42))
+	// Output:
+	// H51E-C5TP-69XR-1
+	// TGWR-VPAD-TT50-6
+}
+
+// This example shows how to load a codec configuration shared between services.
+func ExampleConfig() {
+	var c presents.Config
+	err := json.Unmarshal([]byte(`{"cipher": "present-80", "padding": true, "namespace": "users"}`), &c)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	key := make([]byte, 10)
+	p, err := c.New(key)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(p.Wrap(42))
+
+	// services sharing the codec should agree on its fingerprint
+	fp, err := c.Fingerprint(key)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(fp)
+	// Output:
+	// lNSgVdFiTR2
+	// c6e11ebc6829006c4e62173e606839ea
+}
+
 // This example shows how to use the triple DES cipher instead of PRESENT.
 func ExampleNewTripleDES() {
 	// 24-byte triple DES key
@@ -105,3 +163,128 @@ func ExampleNewWithCipher() {
 	// woSdQdAYuiK
 	// 1213486160
 }
+
+// This example shows how to use the SPECK64/128 cipher instead of PRESENT.
+// NewSimon can be used in the same way.
+func ExampleNewSpeck() {
+	// 16-byte SPECK64/128 key
+	key := make([]byte, 16)
+	p, err := presents.NewSpeck(key, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	s := p.Wrap(1213486160)
+	fmt.Println(s)
+
+	n, err := p.Unwrap(s)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(n)
+	// Output:
+	// qIJVRPpEkfL
+	// 1213486160
+}
+
+// This example shows how to choose the cipher by name, for example from a configuration file.
+func ExampleNewFromSpec() {
+	// 80-bit PRESENT block cipher key
+	key := make([]byte, 10)
+	p, err := presents.NewFromSpec("present-80", key, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(p.Wrap(1213486160))
+
+	_, err = presents.NewFromSpec("present-128", key, nil)
+	fmt.Println(err)
+	// Output:
+	// 90NyXHLckhA
+	// presents: invalid key size 10 for cipher present-128 (want 16 bytes)
+}
+
+// This example shows how to inspect the errors returned by Unwrap, for example to choose an HTTP status code.
+func ExamplePresents_Unwrap_errors() {
+	// 80-bit PRESENT block cipher key
+	key := make([]byte, 10)
+	p, err := presents.New(key, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, s := range []string{"90NyXHLckhA", "90Ny-HLckhA", "zzzzzzzzzzzz"} {
+		n, err := p.Unwrap(s)
+		var invalidChar *presents.InvalidCharError
+		switch {
+		case err == nil:
+			fmt.Println(200, n)
+		case errors.As(err, &invalidChar):
+			fmt.Printf("%d unexpected %q at position %d\n", 400, invalidChar.Char, invalidChar.Pos)
+		case errors.Is(err, presents.ErrOverflow), errors.Is(err, presents.ErrLength):
+			fmt.Println(404, err)
+		}
+	}
+	// Output:
+	// 200 1213486160
+	// 400 unexpected '-' at position 4
+	// 404 presents: value overflows 64 bits
+}
+
+// This example shows how to produce IDs which are easier to read aloud, using proquints or words.
+func ExampleEncoder() {
+	// 80-bit PRESENT block cipher key
+	key := make([]byte, 10)
+	for _, encoder := range []presents.Encoder{presents.Proquint{}, presents.DefaultWordList()} {
+		p, err := presents.New(key, &presents.Options{Encoder: encoder})
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(p.Wrap(1213486160))
+	}
+	// Output:
+	// lufuf-digal-vahoh-rokuj
+	// busy-goddess-erase-vacuum-napkin-cute
+}
+
+// base32Encoder is a custom Encoder using the RFC 4648 base32 alphabet without padding.
+type base32Encoder struct{}
+
+func (base32Encoder) Encode(n uint64) string {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, n)
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b)
+}
+
+func (base32Encoder) Decode(s string) (uint64, error) {
+	b, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(s)
+	if err != nil {
+		return 0, err
+	}
+	if len(b) != 8 {
+		return 0, presents.ErrLength
+	}
+	return binary.BigEndian.Uint64(b), nil
+}
+
+// This example shows how to plug in a custom Encoder, in this case RFC 4648 base32.
+func ExampleEncoder_custom() {
+	// 80-bit PRESENT block cipher key
+	key := make([]byte, 10)
+	p, err := presents.New(key, &presents.Options{Encoder: base32Encoder{}})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	s := p.Wrap(1213486160)
+	fmt.Println(s)
+
+	n, err := p.Unwrap(s)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(n)
+	// Output:
+	// PSZBJR7BES43K
+	// 1213486160
+}
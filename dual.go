@@ -0,0 +1,141 @@
+package presents
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrRawNotAllowed is returned by DualCodec.Unwrap when a raw ID is rejected by its RawPolicy.
+var ErrRawNotAllowed = errors.New("presents: raw IDs are not allowed")
+
+// RawPolicy decides whether a DualCodec accepts the raw decimal ID n.
+// It returns nil to accept it, or an error to reject it.
+type RawPolicy func(n uint64) error
+
+// AllowRaw returns a RawPolicy which accepts every raw ID.
+func AllowRaw() RawPolicy {
+	return func(n uint64) error {
+		return nil
+	}
+}
+
+// AllowRawUntil returns a RawPolicy which accepts raw IDs until deadline,
+// and rejects them with ErrRawNotAllowed afterwards.
+func AllowRawUntil(deadline time.Time) RawPolicy {
+	return func(n uint64) error {
+		if time.Now().After(deadline) {
+			return ErrRawNotAllowed
+		}
+		return nil
+	}
+}
+
+// ReportRaw returns a RawPolicy which calls report with every raw ID before applying policy,
+// so that the remaining raw traffic can be measured.
+// If policy is nil, every raw ID is accepted.
+func ReportRaw(policy RawPolicy, report func(n uint64)) RawPolicy {
+	if policy == nil {
+		policy = AllowRaw()
+	}
+	return func(n uint64) error {
+		report(n)
+		return policy(n)
+	}
+}
+
+// DualCodec unwraps both wrapped strings and raw decimal IDs while obfuscated IDs are being rolled out.
+// New IDs are always wrapped.
+//
+// A string is treated as a raw ID if it is marker followed by one or more decimal digits.
+// With an empty marker, any string made up only of decimal digits is a raw ID,
+// so a wrapped string which happens to contain only digits will be read as a raw ID.
+// With DefaultAlphabet this affects about 2 in a billion wrapped strings;
+// a non-empty marker such as "~" removes the ambiguity entirely as long as it is not in the alphabet.
+type DualCodec struct {
+	presents *Presents
+	marker   string
+	policy   RawPolicy
+}
+
+// NewDualCodec returns a DualCodec which wraps and unwraps using p,
+// and accepts raw IDs prefixed by marker according to policy.
+// If policy is nil, raw IDs are rejected with ErrRawNotAllowed.
+// It returns an error if marker is empty and the encoder of p outputs only digits,
+// since every wrapped string would then look like a raw ID,
+// or if marker is empty and the characters of a custom Encoder are not known.
+// It also returns an error if marker only contains characters which the encoder outputs,
+// since wrapped strings could then start with it.
+func NewDualCodec(p *Presents, marker string, policy RawPolicy) (*DualCodec, error) {
+	chars, known := p.charset()
+	if marker == "" && !known {
+		return nil, errors.New("presents: NewDualCodec: a marker is required when the characters of the Encoder are not known")
+	}
+	if marker == "" && isDecimal(chars) {
+		return nil, errors.New("presents: NewDualCodec: a marker is required when the alphabet contains only digits")
+	}
+	if marker != "" && known && strings.Trim(marker, chars) == "" {
+		return nil, fmt.Errorf("presents: NewDualCodec: marker %q only contains characters from the alphabet", marker)
+	}
+	if policy == nil {
+		policy = func(n uint64) error {
+			return ErrRawNotAllowed
+		}
+	}
+	return &DualCodec{
+		presents: p,
+		marker:   marker,
+		policy:   policy,
+	}, nil
+}
+
+// Wrap converts an unsigned 64-bit integer to a string.
+func (d *DualCodec) Wrap(n uint64) string {
+	return d.presents.Wrap(n)
+}
+
+// Unwrap converts a wrapped string or a raw decimal ID back to an unsigned 64-bit integer.
+// Raw IDs are checked against the RawPolicy, and its error is returned if it rejects them.
+// Accepted raw IDs are then checked and reported like unwrapped strings,
+// so the MaxID, Validate, Canaries and Observer options of the Presents apply to them too.
+func (d *DualCodec) Unwrap(s string) (uint64, error) {
+	if !strings.HasPrefix(s, d.marker) || !isDecimal(s[len(d.marker):]) {
+		return d.presents.Unwrap(s)
+	}
+	p := d.presents
+	if p.observer == nil {
+		return d.unwrapRaw(s)
+	}
+	start := time.Now()
+	n, err := d.unwrapRaw(s)
+	p.observeUnwrap(err, start)
+	return n, err
+}
+
+// unwrapRaw parses the raw ID s and applies the RawPolicy and the checks made by Presents.Unwrap.
+func (d *DualCodec) unwrapRaw(s string) (uint64, error) {
+	n, err := strconv.ParseUint(s[len(d.marker):], 10, 64)
+	if err != nil {
+		return 0, ErrOverflow
+	}
+	if err := d.policy(n); err != nil {
+		return 0, err
+	}
+	return d.presents.finish(context.Background(), n, s)
+}
+
+// isDecimal reports whether s is non-empty and contains only the digits 0-9.
+func isDecimal(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
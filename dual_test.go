@@ -0,0 +1,152 @@
+package presents_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yi-jiayu/presents"
+)
+
+func TestDualCodec_Unwrap(t *testing.T) {
+	p, err := presents.New(make([]byte, 10), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Run("allow raw", func(t *testing.T) {
+		d, err := presents.NewDualCodec(p, "", presents.AllowRaw())
+		if err != nil {
+			t.Fatal(err)
+		}
+		n, err := d.Unwrap("1213486160")
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(1213486160), n)
+		n, err = d.Unwrap("90NyXHLckhA")
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(1213486160), n)
+		_, err = d.Unwrap("99999999999999999999")
+		assert.Equal(t, presents.ErrOverflow, err)
+	})
+	t.Run("deny raw", func(t *testing.T) {
+		d, err := presents.NewDualCodec(p, "", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = d.Unwrap("1213486160")
+		assert.Equal(t, presents.ErrRawNotAllowed, err)
+	})
+	t.Run("allow raw until", func(t *testing.T) {
+		d, err := presents.NewDualCodec(p, "", presents.AllowRawUntil(time.Now().Add(time.Hour)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = d.Unwrap("1213486160")
+		assert.NoError(t, err)
+		d, err = presents.NewDualCodec(p, "", presents.AllowRawUntil(time.Now().Add(-time.Hour)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = d.Unwrap("1213486160")
+		assert.Equal(t, presents.ErrRawNotAllowed, err)
+	})
+	t.Run("report raw", func(t *testing.T) {
+		var reported []uint64
+		d, err := presents.NewDualCodec(p, "", presents.ReportRaw(nil, func(n uint64) {
+			reported = append(reported, n)
+		}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = d.Unwrap("42")
+		assert.NoError(t, err)
+		_, err = d.Unwrap("90NyXHLckhA")
+		assert.NoError(t, err)
+		assert.Equal(t, []uint64{42}, reported)
+	})
+	t.Run("marker", func(t *testing.T) {
+		d, err := presents.NewDualCodec(p, "~", presents.AllowRaw())
+		if err != nil {
+			t.Fatal(err)
+		}
+		n, err := d.Unwrap("~42")
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(42), n)
+		// without the marker, a string of digits is unwrapped
+		n, err = d.Unwrap("42")
+		assert.NoError(t, err)
+		expected, _ := p.Unwrap("42")
+		assert.Equal(t, expected, n)
+	})
+	t.Run("options", func(t *testing.T) {
+		o := new(recordingObserver)
+		p, err := presents.New(make([]byte, 10), presents.WithMaxID(1000), presents.WithObserver(o))
+		if err != nil {
+			t.Fatal(err)
+		}
+		d, err := presents.NewDualCodec(p, "~", presents.AllowRaw())
+		if err != nil {
+			t.Fatal(err)
+		}
+		n, err := d.Unwrap("~42")
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(42), n)
+		_, err = d.Unwrap("~1001")
+		assert.True(t, errors.Is(err, presents.ErrOutOfRange))
+		assert.Equal(t, 1, o.unwrapped)
+		assert.Equal(t, []presents.ErrorKind{presents.KindRejected}, o.failures)
+	})
+}
+
+func TestNewDualCodec(t *testing.T) {
+	p, err := presents.New(make([]byte, 10), &presents.Options{Alphabet: "0123456789"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = presents.NewDualCodec(p, "", presents.AllowRaw())
+	assert.EqualError(t, err, "presents: NewDualCodec: a marker is required when the alphabet contains only digits")
+	d, err := presents.NewDualCodec(p, "raw:", presents.AllowRaw())
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := d.Unwrap(d.Wrap(1213486160))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1213486160), n)
+	_, err = presents.NewDualCodec(p, "1", presents.AllowRaw())
+	assert.EqualError(t, err, `presents: NewDualCodec: marker "1" only contains characters from the alphabet`)
+}
+
+func TestNewDualCodec_encoder(t *testing.T) {
+	perm, err := presents.NewFeistel([]byte("key"), 36)
+	if err != nil {
+		t.Fatal(err)
+	}
+	digits, err := presents.NewFixedWidth("0123456789", 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := presents.Pipeline{Permutation: perm, Encoder: digits}.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = presents.NewDualCodec(p, "", presents.AllowRaw())
+	assert.EqualError(t, err, "presents: NewDualCodec: a marker is required when the alphabet contains only digits")
+	_, err = presents.NewDualCodec(p, "1", presents.AllowRaw())
+	assert.EqualError(t, err, `presents: NewDualCodec: marker "1" only contains characters from the alphabet`)
+	d, err := presents.NewDualCodec(p, "~", presents.AllowRaw())
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := d.Unwrap(d.Wrap(42))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(42), n)
+
+	p, err = presents.Pipeline{Permutation: perm, Encoder: hexEncoder{}}.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = presents.NewDualCodec(p, "", presents.AllowRaw())
+	assert.EqualError(t, err, "presents: NewDualCodec: a marker is required when the characters of the Encoder are not known")
+	_, err = presents.NewDualCodec(p, "~", presents.AllowRaw())
+	assert.NoError(t, err)
+}
@@ -0,0 +1,61 @@
+package presents
+
+import "strings"
+
+// Encoder converts the encrypted 64-bit blocks produced by a Presents to and from text.
+// Alphabet is the default implementation, and others such as Proquint and WordList
+// can be used instead by setting Options.Encoder.
+//
+// Decode must be the inverse of Encode for every 64-bit value,
+// and should return errors matching the sentinel errors, such as ErrLength or ErrInvalidChar, where they apply.
+type Encoder interface {
+	Encode(n uint64) string
+	Decode(s string) (uint64, error)
+}
+
+// AppendEncoder is an Encoder which can also append its output to a byte slice, avoiding an allocation.
+// Presents.AppendWrap uses it when it is available.
+type AppendEncoder interface {
+	Encoder
+	AppendEncode(dst []byte, n uint64) []byte
+}
+
+// BoundedEncoder is an Encoder which can only represent integers below 2^Bits(),
+// such as FixedWidth. Pipeline.Build checks that its stages never produce anything larger.
+type BoundedEncoder interface {
+	Encoder
+	Bits() int
+}
+
+// charsetEncoder is implemented by the built-in Encoders, which report every character their output may contain,
+// so that Pipeline.Build can check that a Grouping does not remove any of them.
+type charsetEncoder interface {
+	charset() string
+}
+
+// charset returns the characters which the Encoder of p may output, and whether they are known.
+func (p *Presents) charset() (string, bool) {
+	if p.encoder == nil {
+		return string(p.alphabet), true
+	}
+	if e, ok := p.encoder.(charsetEncoder); ok {
+		return e.charset(), true
+	}
+	return "", false
+}
+
+func (a Alphabet) charset() string {
+	return string(a)
+}
+
+func (Proquint) charset() string {
+	return proquintConsonants + proquintVowels + "-"
+}
+
+func (w *WordList) charset() string {
+	return strings.Join(w.words, "") + w.separator
+}
+
+func (f *FixedWidth) charset() string {
+	return string(f.alphabet)
+}
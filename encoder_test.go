@@ -0,0 +1,53 @@
+package presents_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yi-jiayu/presents"
+)
+
+var (
+	_ presents.AppendEncoder = presents.DefaultAlphabet
+	_ presents.AppendEncoder = presents.Proquint{}
+	_ presents.Encoder       = presents.DefaultWordList()
+)
+
+func TestOptions_Encoder_alphabet(t *testing.T) {
+	key := make([]byte, 10)
+	expected, err := presents.New(key, &presents.Options{Alphabet: "0123456789abcdef", GroupSize: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := presents.New(key, &presents.Options{Encoder: presents.Alphabet("0123456789abcdef"), GroupSize: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, expected.Wrap(1213486160), p.Wrap(1213486160))
+
+	_, err = presents.New(key, &presents.Options{Encoder: presents.DefaultAlphabet, Alphabet: "0123456789abcdef"})
+	assert.EqualError(t, err, "presents: NewWithCipher: Encoder and Alphabet are both set")
+	_, err = presents.New(key, &presents.Options{Encoder: presents.Alphabet("0120")})
+	assert.EqualError(t, err, "presents: all characters in alphabet must be unique")
+}
+
+func TestPresents_AppendWrap(t *testing.T) {
+	key := make([]byte, 10)
+	for _, options := range []*presents.Options{
+		nil,
+		{Encoder: presents.Proquint{}},
+		{Encoder: presents.DefaultWordList()},
+		{GroupSize: 4, Padding: true},
+		{Blocklist: presents.DefaultBlocklist},
+	} {
+		p, err := presents.New(key, options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dst := []byte("prefix:")
+		for n := uint64(0); n < 100; n++ {
+			dst = p.AppendWrap(dst[:7], n)
+			assert.Equal(t, "prefix:"+p.Wrap(n), string(dst))
+		}
+	}
+}
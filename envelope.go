@@ -0,0 +1,153 @@
+package presents
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// envelopeVersions assigns a version character to each built-in cipher.
+// These assignments are part of the token format and must never change.
+var envelopeVersions = map[string]byte{
+	"present-80":  'p',
+	"present-128": 'q',
+	"3des":        'd',
+	"blowfish":    'b',
+	"speck64/96":  's',
+	"speck64/128": 't',
+	"simon64/128": 'm',
+	"gift64/128":  'g',
+	"prince":      'r',
+}
+
+// EnvelopeKey describes one of the keys an Envelope can wrap and unwrap tokens with.
+type EnvelopeKey struct {
+	// ID identifies the key inside tokens.
+	// It is either empty or a single character from DefaultAlphabet.
+	ID string
+	// Cipher is the name of a built-in cipher, as accepted by NewFromSpec.
+	Cipher string
+	// Key is the key for Cipher.
+	Key []byte
+	// Options customises the alphabet used for the payload.
+	Options *Options
+}
+
+// Envelope converts integers to and from self-describing versioned tokens,
+// so that tokens created with different ciphers, keys or alphabets can be told apart.
+//
+// Version 1 of the token format is
+//
+//	token   = version [key-id] payload
+//	version = one ASCII letter identifying the cipher
+//	key-id  = one character from DefaultAlphabet
+//	payload = the output of Presents.Wrap for the key
+//
+// The version letter identifies the cipher as follows:
+//
+//	p  present-80     q  present-128    d  3des
+//	b  blowfish       s  speck64/96     t  speck64/128
+//	m  simon64/128    g  gift64/128     r  prince
+//
+// A lowercase letter means the token has no key ID and the payload starts immediately after it.
+// An uppercase letter means the next character is the key ID.
+// Letters not listed above are reserved for future ciphers and format versions.
+//
+// The alphabet used for the payload is not recorded in the token;
+// it is part of the configuration of the key identified by the version and key ID.
+//
+// An Envelope is safe for concurrent use by multiple goroutines.
+type Envelope struct {
+	primary *Presents
+	header  string
+	codecs  map[string]*Presents
+}
+
+// NewEnvelope creates an Envelope which wraps integers using primary,
+// and unwraps tokens created with primary or any of others.
+// Every key must have a different combination of cipher and key ID.
+func NewEnvelope(primary EnvelopeKey, others ...EnvelopeKey) (*Envelope, error) {
+	e := &Envelope{
+		codecs: make(map[string]*Presents),
+	}
+	for i, k := range append([]EnvelopeKey{primary}, others...) {
+		header, err := k.header()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := e.codecs[header]; ok {
+			return nil, fmt.Errorf("presents: NewEnvelope: duplicate cipher %s and key ID %q", k.Cipher, k.ID)
+		}
+		p, err := NewFromSpec(k.Cipher, k.Key, k.Options)
+		if err != nil {
+			return nil, err
+		}
+		e.codecs[header] = p
+		if i == 0 {
+			e.primary = p
+			e.header = header
+		}
+	}
+	return e, nil
+}
+
+// header returns the version character and key ID which start tokens made with k.
+func (k EnvelopeKey) header() (string, error) {
+	version, ok := envelopeVersions[k.Cipher]
+	if !ok {
+		return "", fmt.Errorf("presents: NewEnvelope: cipher %q has no envelope version", k.Cipher)
+	}
+	switch {
+	case k.ID == "":
+		return string(version), nil
+	case len(k.ID) == 1 && strings.IndexByte(string(DefaultAlphabet), k.ID[0]) != -1:
+		return string(version-'a'+'A') + k.ID, nil
+	default:
+		return "", fmt.Errorf("presents: NewEnvelope: key ID %q is not a single character from DefaultAlphabet", k.ID)
+	}
+}
+
+// isEnvelopeVersion reports whether version is assigned to one of the built-in ciphers.
+func isEnvelopeVersion(version byte) bool {
+	for _, v := range envelopeVersions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// Wrap converts an unsigned 64-bit integer to a token using the primary key.
+func (e *Envelope) Wrap(n uint64) string {
+	return e.header + e.primary.Wrap(n)
+}
+
+// Unwrap converts a token created by any of the keys of e back to an unsigned 64-bit integer.
+// It returns an error matching ErrUnknownVersion or ErrUnknownKeyID if the token's version or key ID does not match any key,
+// or if the payload cannot be converted.
+func (e *Envelope) Unwrap(s string) (uint64, error) {
+	if s == "" {
+		return 0, ErrLength
+	}
+	n := 1
+	if 'A' <= s[0] && s[0] <= 'Z' {
+		n = 2
+	}
+	if len(s) < n {
+		return 0, ErrLength
+	}
+	p, ok := e.codecs[s[:n]]
+	if !ok {
+		if n == 2 && isEnvelopeVersion(s[0]-'A'+'a') {
+			return 0, fmt.Errorf("%w %q for version %q", ErrUnknownKeyID, s[1:2], s[:1])
+		}
+		return 0, fmt.Errorf("%w %q", ErrUnknownVersion, s[:n])
+	}
+	id, err := p.Unwrap(s[n:])
+	var invalidChar *InvalidCharError
+	if errors.As(err, &invalidChar) {
+		// report the position within the whole token
+		return 0, &InvalidCharError{Pos: invalidChar.Pos + n, Char: invalidChar.Char}
+	}
+	return id, err
+}
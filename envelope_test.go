@@ -0,0 +1,119 @@
+package presents_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yi-jiayu/presents"
+)
+
+// sequentialKey returns a key of the given size with bytes 0, 1, 2, ...
+func sequentialKey(size int) []byte {
+	key := make([]byte, size)
+	for i := range key {
+		key[i] = byte(i)
+	}
+	return key
+}
+
+// These tokens are frozen: if this test fails, tokens minted by earlier releases can no longer be read.
+func TestEnvelope_goldenVectors(t *testing.T) {
+	vectors := []struct {
+		key      presents.EnvelopeKey
+		n        uint64
+		expected string
+	}{
+		{presents.EnvelopeKey{Cipher: "present-80", Key: sequentialKey(10)}, 1213486160, "p7tW5cRqGs95"},
+		{presents.EnvelopeKey{Cipher: "present-80", Key: sequentialKey(10), ID: "7"}, 1213486160, "P77tW5cRqGs95"},
+		{presents.EnvelopeKey{Cipher: "present-128", Key: sequentialKey(16)}, 1213486160, "qgN41U8uPcSI"},
+		{presents.EnvelopeKey{Cipher: "3des", Key: sequentialKey(24)}, 1213486160, "dKgr6FJFKoUE"},
+		{presents.EnvelopeKey{Cipher: "blowfish", Key: sequentialKey(56)}, 1213486160, "bIynTqi8ZWXG"},
+		{presents.EnvelopeKey{Cipher: "speck64/96", Key: sequentialKey(12)}, 1213486160, "s2nO944fE8sJ"},
+		{presents.EnvelopeKey{Cipher: "speck64/128", Key: sequentialKey(16)}, 1213486160, "tYRq6B8AOfpC"},
+		{presents.EnvelopeKey{Cipher: "simon64/128", Key: sequentialKey(16)}, 1213486160, "mT541lUPAhn1"},
+		{presents.EnvelopeKey{Cipher: "gift64/128", Key: sequentialKey(16)}, 1213486160, "gWD4UVfSWsa5"},
+		{presents.EnvelopeKey{Cipher: "prince", Key: sequentialKey(16), ID: "z"}, 1213486160, "RzIeoKn98MaZI"},
+		{presents.EnvelopeKey{Cipher: "present-80", Key: sequentialKey(10), Options: &presents.Options{Shuffle: true, Seed: 1}}, 42, "ppcRe38y09aJ"},
+	}
+	for _, v := range vectors {
+		e, err := presents.NewEnvelope(v.key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, v.expected, e.Wrap(v.n), "%s %q", v.key.Cipher, v.key.ID)
+		n, err := e.Unwrap(v.expected)
+		assert.NoError(t, err)
+		assert.Equal(t, v.n, n)
+	}
+}
+
+func TestEnvelope_Unwrap(t *testing.T) {
+	oldKey := presents.EnvelopeKey{Cipher: "present-80", Key: sequentialKey(10), ID: "1"}
+	newKey := presents.EnvelopeKey{Cipher: "speck64/128", Key: sequentialKey(16), ID: "2",
+		Options: &presents.Options{Alphabet: "0123456789abcdef"}}
+	legacyKey := presents.EnvelopeKey{Cipher: "3des", Key: sequentialKey(24)}
+	old, err := presents.NewEnvelope(oldKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacy, err := presents.NewEnvelope(legacyKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := presents.NewEnvelope(newKey, oldKey, legacyKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Run("dispatches on version and key ID", func(t *testing.T) {
+		for _, s := range []string{e.Wrap(1213486160), old.Wrap(1213486160), legacy.Wrap(1213486160)} {
+			n, err := e.Unwrap(s)
+			assert.NoError(t, err, s)
+			assert.Equal(t, uint64(1213486160), n, s)
+		}
+		assert.Equal(t, "T2", e.Wrap(1213486160)[:2])
+	})
+	t.Run("unknown version", func(t *testing.T) {
+		_, err := e.Unwrap("x123")
+		assert.True(t, errors.Is(err, presents.ErrUnknownVersion))
+		assert.EqualError(t, err, `presents: unknown token version "x"`)
+	})
+	t.Run("unknown key ID", func(t *testing.T) {
+		_, err := e.Unwrap("P3123")
+		assert.True(t, errors.Is(err, presents.ErrUnknownKeyID))
+		assert.EqualError(t, err, `presents: unknown key ID "3" for version "P"`)
+		_, err = e.Unwrap("X3123")
+		assert.True(t, errors.Is(err, presents.ErrUnknownVersion))
+		assert.EqualError(t, err, `presents: unknown token version "X3"`)
+	})
+	t.Run("too short", func(t *testing.T) {
+		_, err := e.Unwrap("")
+		assert.Equal(t, presents.ErrLength, err)
+		_, err = e.Unwrap("P")
+		assert.Equal(t, presents.ErrLength, err)
+	})
+	t.Run("invalid character", func(t *testing.T) {
+		_, err := e.Unwrap("T2ab!")
+		assert.Equal(t, &presents.InvalidCharError{Pos: 4, Char: '!'}, err)
+	})
+}
+
+func TestNewEnvelope(t *testing.T) {
+	key := presents.EnvelopeKey{Cipher: "present-80", Key: sequentialKey(10)}
+	t.Run("duplicate key", func(t *testing.T) {
+		_, err := presents.NewEnvelope(key, key)
+		assert.EqualError(t, err, `presents: NewEnvelope: duplicate cipher present-80 and key ID ""`)
+	})
+	t.Run("invalid key ID", func(t *testing.T) {
+		_, err := presents.NewEnvelope(presents.EnvelopeKey{Cipher: "present-80", Key: sequentialKey(10), ID: "ab"})
+		assert.EqualError(t, err, `presents: NewEnvelope: key ID "ab" is not a single character from DefaultAlphabet`)
+	})
+	t.Run("cipher without version", func(t *testing.T) {
+		_, err := presents.NewEnvelope(presents.EnvelopeKey{Cipher: "rot13"})
+		assert.EqualError(t, err, `presents: NewEnvelope: cipher "rot13" has no envelope version`)
+	})
+	t.Run("invalid key size", func(t *testing.T) {
+		_, err := presents.NewEnvelope(presents.EnvelopeKey{Cipher: "present-80", Key: sequentialKey(16)})
+		assert.IsType(t, &presents.KeySizeError{}, err)
+	})
+}
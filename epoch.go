@@ -0,0 +1,141 @@
+package presents
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+var (
+	// ErrNoEpoch is returned when an ID, or the decryption of a token, does not fall into any epoch.
+	ErrNoEpoch = errors.New("presents: no matching epoch")
+	// ErrAmbiguous is returned when a token decrypts to an ID inside the range of more than one epoch.
+	ErrAmbiguous = errors.New("presents: token matches more than one epoch")
+)
+
+// Epoch is a range of IDs which are all wrapped using the same Presents.
+type Epoch struct {
+	// Min and Max are the smallest and largest IDs in the epoch.
+	Min, Max uint64
+	// Presents is used to wrap and unwrap IDs in the epoch.
+	Presents *Presents
+}
+
+// contains reports whether n is inside the range of e.
+func (e Epoch) contains(n uint64) bool {
+	return e.Min <= n && n <= e.Max
+}
+
+// EpochCodec rotates keys based on ID ranges instead of recording a key ID in each token.
+//
+// Each ID is wrapped using the epoch its value falls into, so tokens stay the same length as Presents.Wrap output.
+// To unwrap a token, every epoch tries to decrypt it, and the single decryption which lands inside
+// the range of the epoch that produced it is accepted.
+// A token from one epoch decrypts to a uniformly random value under the key of another epoch,
+// so the chance of it also landing inside that other epoch's range is proportional to the size of the range.
+// AmbiguityRate estimates how often this happens.
+//
+// An EpochCodec is safe for concurrent use by multiple goroutines.
+type EpochCodec struct {
+	epochs []Epoch
+}
+
+// NewEpochCodec creates an EpochCodec from a set of epochs with non-overlapping ranges.
+// A common setup is two epochs split at a watermark W: {Min: 0, Max: W - 1} using the old key
+// and {Min: W, Max: C} using the new key, where C is a ceiling the IDs will never reach.
+// It returns an error if an epoch extends outside the domain of the Permutation of its Presents.
+// Ranges should be kept as small as possible, since a token is ambiguous whenever it also
+// decrypts into another epoch; an epoch extending to math.MaxUint64 would match almost every token.
+func NewEpochCodec(epochs ...Epoch) (*EpochCodec, error) {
+	if len(epochs) == 0 {
+		return nil, errors.New("presents: NewEpochCodec: no epochs")
+	}
+	sorted := append([]Epoch(nil), epochs...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Min < sorted[j].Min
+	})
+	for i, e := range sorted {
+		if e.Presents == nil {
+			return nil, fmt.Errorf("presents: NewEpochCodec: epoch [%d, %d] has no Presents", e.Min, e.Max)
+		}
+		if e.Min > e.Max {
+			return nil, fmt.Errorf("presents: NewEpochCodec: epoch [%d, %d] is empty", e.Min, e.Max)
+		}
+		if bits := e.Presents.permutation.Bits(); bits < 64 && e.Max>>uint(bits) != 0 {
+			return nil, fmt.Errorf("presents: NewEpochCodec: epoch [%d, %d] is outside the domain of its %d-bit Permutation",
+				e.Min, e.Max, bits)
+		}
+		if i > 0 && sorted[i-1].Max >= e.Min {
+			return nil, fmt.Errorf("presents: NewEpochCodec: epochs [%d, %d] and [%d, %d] overlap",
+				sorted[i-1].Min, sorted[i-1].Max, e.Min, e.Max)
+		}
+	}
+	return &EpochCodec{epochs: sorted}, nil
+}
+
+// Wrap converts an unsigned 64-bit integer to a string using the epoch it falls into.
+// It returns ErrNoEpoch if n is not inside any epoch.
+func (c *EpochCodec) Wrap(n uint64) (string, error) {
+	i := sort.Search(len(c.epochs), func(i int) bool {
+		return c.epochs[i].Max >= n
+	})
+	if i == len(c.epochs) || !c.epochs[i].contains(n) {
+		return "", ErrNoEpoch
+	}
+	return c.epochs[i].Presents.TryWrap(n)
+}
+
+// Unwrap converts a string back to an unsigned 64-bit integer.
+// It returns ErrAmbiguous if the string decrypts into the range of more than one epoch,
+// and ErrNoEpoch if it does not decrypt into the range of any epoch.
+// If no epoch can decode the string at all, the error from the first epoch is returned instead.
+func (c *EpochCodec) Unwrap(s string) (uint64, error) {
+	var result uint64
+	var matches int
+	var decodeErr error
+	decoded := false
+	for _, e := range c.epochs {
+		n, err := e.Presents.Unwrap(s)
+		if err != nil {
+			if decodeErr == nil {
+				decodeErr = err
+			}
+			continue
+		}
+		decoded = true
+		if e.contains(n) {
+			result = n
+			matches++
+		}
+	}
+	switch {
+	case matches == 1:
+		return result, nil
+	case matches > 1:
+		return 0, ErrAmbiguous
+	case !decoded:
+		return 0, decodeErr
+	default:
+		return 0, ErrNoEpoch
+	}
+}
+
+// AmbiguityRate estimates the probability that a valid token from the epoch with the largest
+// rival ranges also decrypts into the range of another epoch, making Unwrap return ErrAmbiguous.
+// It assumes that decrypting a token under the wrong key yields a uniformly random 64-bit value.
+func (c *EpochCodec) AmbiguityRate() float64 {
+	var worst float64
+	for i := range c.epochs {
+		clean := 1.0
+		for j, e := range c.epochs {
+			if i != j {
+				clean *= 1 - (float64(e.Max-e.Min)+1)/(math.MaxUint64+1.0)
+			}
+		}
+		if 1-clean > worst {
+			worst = 1 - clean
+		}
+	}
+	return worst
+}
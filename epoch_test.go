@@ -0,0 +1,157 @@
+package presents_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yi-jiayu/presents"
+)
+
+func newEpochPresents(t *testing.T, seed byte) *presents.Presents {
+	key := make([]byte, 16)
+	key[0] = seed
+	p, err := presents.NewSpeck(key, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestEpochCodec(t *testing.T) {
+	const watermark, ceiling = 1000000, 1 << 40
+	old, current := newEpochPresents(t, 1), newEpochPresents(t, 2)
+	c, err := presents.NewEpochCodec(
+		presents.Epoch{Min: watermark, Max: ceiling, Presents: current},
+		presents.Epoch{Min: 0, Max: watermark - 1, Presents: old},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range []uint64{0, 1, watermark - 1, watermark, watermark + 1, ceiling} {
+		s, err := c.Wrap(n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n < watermark {
+			assert.Equal(t, old.Wrap(n), s)
+		} else {
+			assert.Equal(t, current.Wrap(n), s)
+		}
+		m, err := c.Unwrap(s)
+		assert.NoError(t, err)
+		assert.Equal(t, n, m)
+	}
+	_, err = c.Unwrap("!")
+	assert.Error(t, err)
+	_, err = c.Wrap(ceiling + 1)
+	assert.Equal(t, presents.ErrNoEpoch, err)
+}
+
+func TestEpochCodec_noEpoch(t *testing.T) {
+	c, err := presents.NewEpochCodec(presents.Epoch{Min: 10, Max: 20, Presents: newEpochPresents(t, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.Wrap(21)
+	assert.Equal(t, presents.ErrNoEpoch, err)
+	_, err = c.Wrap(9)
+	assert.Equal(t, presents.ErrNoEpoch, err)
+	_, err = c.Unwrap(newEpochPresents(t, 1).Wrap(21))
+	assert.Equal(t, presents.ErrNoEpoch, err)
+}
+
+func TestNewEpochCodec(t *testing.T) {
+	p := newEpochPresents(t, 1)
+	_, err := presents.NewEpochCodec()
+	assert.EqualError(t, err, "presents: NewEpochCodec: no epochs")
+	_, err = presents.NewEpochCodec(presents.Epoch{Min: 0, Max: 10, Presents: p}, presents.Epoch{Min: 10, Max: 20, Presents: p})
+	assert.EqualError(t, err, "presents: NewEpochCodec: epochs [0, 10] and [10, 20] overlap")
+	_, err = presents.NewEpochCodec(presents.Epoch{Min: 5, Max: 4, Presents: p})
+	assert.EqualError(t, err, "presents: NewEpochCodec: epoch [5, 4] is empty")
+	_, err = presents.NewEpochCodec(presents.Epoch{Min: 0, Max: 4})
+	assert.EqualError(t, err, "presents: NewEpochCodec: epoch [0, 4] has no Presents")
+
+	perm, err := presents.NewFeistel([]byte("key"), 36)
+	if err != nil {
+		t.Fatal(err)
+	}
+	small, err := presents.Pipeline{Permutation: perm}.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = presents.NewEpochCodec(presents.Epoch{Min: 0, Max: 1 << 36, Presents: small})
+	assert.EqualError(t, err, "presents: NewEpochCodec: epoch [0, 68719476736] is outside the domain of its 36-bit Permutation")
+	c, err := presents.NewEpochCodec(presents.Epoch{Min: 0, Max: 1<<36 - 1, Presents: small})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := c.Wrap(1<<36 - 1)
+	assert.NoError(t, err)
+	assert.Equal(t, small.Wrap(1<<36-1), s)
+}
+
+// measureAmbiguity wraps random IDs from each epoch and returns the fraction which fail to unwrap with ErrAmbiguous.
+// Every other token must unwrap to its original ID.
+func measureAmbiguity(t *testing.T, c *presents.EpochCodec, epochs []presents.Epoch, samples int) float64 {
+	r := rand.New(rand.NewSource(1))
+	ambiguous := 0
+	for i := 0; i < samples; i++ {
+		e := epochs[i%len(epochs)]
+		n := e.Min + r.Uint64()%(e.Max-e.Min+1)
+		s, err := c.Wrap(n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m, err := c.Unwrap(s)
+		if err == presents.ErrAmbiguous {
+			ambiguous++
+			continue
+		}
+		if assert.NoError(t, err) {
+			assert.Equal(t, n, m)
+		}
+	}
+	return float64(ambiguous) / float64(samples)
+}
+
+func TestEpochCodec_ambiguityRate(t *testing.T) {
+	t.Run("small ranges", func(t *testing.T) {
+		epochs := []presents.Epoch{
+			{Min: 0, Max: 1<<32 - 1, Presents: newEpochPresents(t, 1)},
+			{Min: 1 << 32, Max: 1<<33 - 1, Presents: newEpochPresents(t, 2)},
+			{Min: 1 << 33, Max: 1<<34 - 1, Presents: newEpochPresents(t, 3)},
+		}
+		c, err := presents.NewEpochCodec(epochs...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.InDelta(t, 3.0/(1<<32), c.AmbiguityRate(), 1e-12)
+		assert.Equal(t, 0.0, measureAmbiguity(t, c, epochs, 3000))
+	})
+	t.Run("halves", func(t *testing.T) {
+		epochs := []presents.Epoch{
+			{Min: 0, Max: 1<<63 - 1, Presents: newEpochPresents(t, 1)},
+			{Min: 1 << 63, Max: math.MaxUint64, Presents: newEpochPresents(t, 2)},
+		}
+		c, err := presents.NewEpochCodec(epochs...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.InDelta(t, 0.5, c.AmbiguityRate(), 1e-12)
+		assert.InDelta(t, 0.5, measureAmbiguity(t, c, epochs, 4000), 0.05)
+	})
+	t.Run("quarters", func(t *testing.T) {
+		epochs := []presents.Epoch{
+			{Min: 0, Max: 1<<62 - 1, Presents: newEpochPresents(t, 1)},
+			{Min: 1 << 62, Max: 1<<63 - 1, Presents: newEpochPresents(t, 2)},
+		}
+		c, err := presents.NewEpochCodec(epochs...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.InDelta(t, 0.25, c.AmbiguityRate(), 1e-12)
+		assert.InDelta(t, 0.25, measureAmbiguity(t, c, epochs, 4000), 0.05)
+	})
+}
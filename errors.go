@@ -0,0 +1,114 @@
+package presents
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Sentinel errors, which can be checked for using errors.Is.
+var (
+	// ErrInvalidChar is matched by every *InvalidCharError.
+	ErrInvalidChar = errors.New("presents: invalid character")
+	// ErrOverflow is returned when a string encodes a value which does not fit in 64 bits.
+	ErrOverflow = errors.New("presents: value overflows 64 bits")
+	// ErrLength is returned when a string is too short or too long to be valid.
+	ErrLength = errors.New("presents: invalid length")
+	// ErrChecksum is returned when a string fails an integrity check.
+	ErrChecksum = errors.New("presents: checksum mismatch")
+	// ErrNonCanonical is returned when a string decodes successfully but is not the form Wrap would produce.
+	ErrNonCanonical = errors.New("presents: string is not in canonical form")
+	// ErrUnknownCipher is returned when a cipher name has not been registered.
+	ErrUnknownCipher = errors.New("presents: unknown cipher")
+	// ErrUnknownVersion is returned when a token starts with an unrecognised version.
+	ErrUnknownVersion = errors.New("presents: unknown token version")
+	// ErrUnknownKeyID is returned when a token has a recognised version but a key ID which does not match any key.
+	ErrUnknownKeyID = errors.New("presents: unknown key ID")
+	// ErrDomain is returned by TryWrap, WrapAll and WrapStream when an integer is too large for the Permutation of a Pipeline.
+	ErrDomain = errors.New("presents: ID outside the domain of the permutation")
+	// ErrRejected is matched by every *RejectedError.
+	ErrRejected = errors.New("presents: ID rejected")
+	// ErrOutOfRange is the reason for a *RejectedError when an unwrapped ID is larger than Options.MaxID.
+	ErrOutOfRange = errors.New("presents: ID exceeds MaxID")
+	// ErrSelfTest is matched by the errors returned by SelfTest.
+	ErrSelfTest = errors.New("presents: self-test failed")
+)
+
+// InvalidCharError is returned when a string contains a character which is not in the alphabet.
+type InvalidCharError struct {
+	// Pos is the byte offset of the character in the string.
+	Pos int
+	// Char is the invalid character.
+	Char rune
+}
+
+func (e *InvalidCharError) Error() string {
+	return fmt.Sprintf("presents: invalid character %q at position %d", e.Char, e.Pos)
+}
+
+// Is reports whether target is ErrInvalidChar.
+func (e *InvalidCharError) Is(target error) bool {
+	return target == ErrInvalidChar
+}
+
+// KeySizeError is returned when a key has the wrong length for the cipher it is used with.
+type KeySizeError struct {
+	// Cipher is the name of the cipher.
+	Cipher string
+	// Size is the length of the key in bytes.
+	Size int
+	// Valid lists the key lengths in bytes accepted by the cipher.
+	Valid []int
+	// Err is the error returned by the underlying cipher package, if any,
+	// such as a present.KeySizeError.
+	Err error
+}
+
+func (e *KeySizeError) Error() string {
+	// consecutive sizes are collapsed into ranges, such as 1-56
+	var valid []string
+	for i := 0; i < len(e.Valid); {
+		j := i
+		for j+1 < len(e.Valid) && e.Valid[j+1] == e.Valid[j]+1 {
+			j++
+		}
+		if j > i {
+			valid = append(valid, strconv.Itoa(e.Valid[i])+"-"+strconv.Itoa(e.Valid[j]))
+		} else {
+			valid = append(valid, strconv.Itoa(e.Valid[i]))
+		}
+		i = j + 1
+	}
+	return fmt.Sprintf("presents: invalid key size %d for cipher %s (want %s bytes)", e.Size, e.Cipher, strings.Join(valid, ", "))
+}
+
+// Unwrap returns the error from the underlying cipher package.
+func (e *KeySizeError) Unwrap() error {
+	return e.Err
+}
+
+// RejectedError is returned when a string unwraps to an ID which is larger than Options.MaxID
+// or fails Options.Validate.
+// The ID itself is not included, since it is usually the decryption of a guessed string.
+type RejectedError struct {
+	// Err is ErrOutOfRange or the error returned by Options.Validate.
+	Err error
+}
+
+func (e *RejectedError) Error() string {
+	if e.Err == ErrOutOfRange {
+		return e.Err.Error()
+	}
+	return "presents: ID rejected: " + e.Err.Error()
+}
+
+// Is reports whether target is ErrRejected.
+func (e *RejectedError) Is(target error) bool {
+	return target == ErrRejected
+}
+
+// Unwrap returns the reason the ID was rejected.
+func (e *RejectedError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,121 @@
+package presents_test
+
+import (
+	"crypto/des"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yi-jiayu/PRESENT.go"
+	"github.com/yi-jiayu/presents"
+)
+
+func TestPresents_Unwrap_errors(t *testing.T) {
+	p, err := presents.New(make([]byte, 10), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Run("invalid character", func(t *testing.T) {
+		_, err := p.Unwrap("90Ny-HLckhA")
+		assert.Equal(t, &presents.InvalidCharError{Pos: 4, Char: '-'}, err)
+		assert.True(t, errors.Is(err, presents.ErrInvalidChar))
+		assert.EqualError(t, err, `presents: invalid character '-' at position 4`)
+	})
+	t.Run("multibyte character", func(t *testing.T) {
+		_, err := p.Unwrap("90Nyé")
+		assert.Equal(t, &presents.InvalidCharError{Pos: 4, Char: 'é'}, err)
+	})
+	t.Run("overflow", func(t *testing.T) {
+		_, err := p.Unwrap("zzzzzzzzzzz")
+		assert.Equal(t, presents.ErrOverflow, err)
+		_, err = p.Unwrap("000000000001")
+		assert.Equal(t, presents.ErrOverflow, err)
+	})
+	t.Run("trailing zero digits", func(t *testing.T) {
+		n, err := p.Unwrap("90NyXHLckhA000")
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(1213486160), n)
+	})
+	t.Run("empty", func(t *testing.T) {
+		_, err := p.Unwrap("")
+		assert.Equal(t, presents.ErrLength, err)
+	})
+}
+
+func TestKeySizeError(t *testing.T) {
+	t.Run("present", func(t *testing.T) {
+		_, err := presents.New(make([]byte, 11), nil)
+		var keySizeErr *presents.KeySizeError
+		if assert.True(t, errors.As(err, &keySizeErr)) {
+			assert.Equal(t, 11, keySizeErr.Size)
+			assert.Equal(t, []int{10, 16}, keySizeErr.Valid)
+		}
+		var presentErr present.KeySizeError
+		assert.True(t, errors.As(err, &presentErr))
+		assert.EqualError(t, err, "presents: invalid key size 11 for cipher present (want 10, 16 bytes)")
+	})
+	t.Run("triple des", func(t *testing.T) {
+		_, err := presents.NewTripleDES(make([]byte, 16), nil)
+		var desErr des.KeySizeError
+		assert.True(t, errors.As(err, &desErr))
+		assert.EqualError(t, err, "presents: invalid key size 16 for cipher 3des (want 24 bytes)")
+	})
+}
+
+func TestBulkError(t *testing.T) {
+	p, err := presents.New(make([]byte, 10), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = p.UnwrapAll([]string{"90NyXHLckhA", "", "!"}, 1)
+	assert.True(t, errors.Is(err, presents.ErrLength))
+	var invalidChar *presents.InvalidCharError
+	if assert.True(t, errors.As(err, &invalidChar)) {
+		assert.Equal(t, '!', invalidChar.Char)
+	}
+}
+
+func TestRejectedError(t *testing.T) {
+	errDeleted := errors.New("deleted")
+	p, err := presents.New(make([]byte, 10), &presents.Options{
+		MaxID: 1 << 32,
+		Validate: func(n uint64) error {
+			if n == 1000 {
+				return errDeleted
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Run("in range", func(t *testing.T) {
+		n, err := p.Unwrap(p.Wrap(1 << 32))
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(1<<32), n)
+	})
+	t.Run("out of range", func(t *testing.T) {
+		_, err := p.Unwrap(p.Wrap(1<<32 + 1))
+		assert.Equal(t, &presents.RejectedError{Err: presents.ErrOutOfRange}, err)
+		assert.True(t, errors.Is(err, presents.ErrRejected))
+		assert.True(t, errors.Is(err, presents.ErrOutOfRange))
+		assert.EqualError(t, err, "presents: ID exceeds MaxID")
+	})
+	t.Run("validate", func(t *testing.T) {
+		_, err := p.Unwrap(p.Wrap(1000))
+		assert.True(t, errors.Is(err, presents.ErrRejected))
+		assert.True(t, errors.Is(err, errDeleted))
+		assert.False(t, errors.Is(err, presents.ErrOutOfRange))
+		assert.EqualError(t, err, "presents: ID rejected: deleted")
+	})
+	t.Run("random strings", func(t *testing.T) {
+		// almost every string decrypts to an ID far above MaxID
+		rejected := 0
+		for _, s := range []string{"abcdefghij", "HelloWorld", "zzzzzzzzzz", "0123456789", "ZZZZZZZZZZ"} {
+			if _, err := p.Unwrap(s); errors.Is(err, presents.ErrRejected) {
+				rejected++
+			}
+		}
+		assert.Equal(t, 5, rejected)
+	})
+}
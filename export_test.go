@@ -0,0 +1,13 @@
+package presents
+
+import "expvar"
+
+// Unregister lets the external tests remove the ciphers they register.
+var Unregister = unregister
+
+// NewUnpublishedExpvarObserver lets the external tests inspect an ExpvarObserver
+// without publishing it, since expvar names cannot be reused when tests are repeated.
+func NewUnpublishedExpvarObserver() (*ExpvarObserver, *expvar.Map) {
+	m := new(expvar.Map)
+	return newExpvarObserver(m), m
+}
@@ -0,0 +1,73 @@
+package presents
+
+import (
+	"fmt"
+	"math/bits"
+	"strings"
+)
+
+// FixedWidth is a BoundedEncoder which writes integers as exactly Width digits of an Alphabet,
+// least significant first, for codes which must have a fixed length such as 12-digit numeric tickets.
+// It can only represent integers below 2^Bits(), the largest power of two which fits in Width digits,
+// so it is usually combined with a Permutation from NewFeistel.
+type FixedWidth struct {
+	alphabet Alphabet
+	width    int
+	bits     int
+}
+
+// NewFixedWidth returns a FixedWidth encoder using width digits of a.
+func NewFixedWidth(a Alphabet, width int) (*FixedWidth, error) {
+	a, err := newAlphabet(string(a))
+	if err != nil {
+		return nil, err
+	}
+	if len(a) < 2 {
+		return nil, fmt.Errorf("presents: NewFixedWidth: alphabet must have at least 2 characters")
+	}
+	if width < 1 || width > a.encodedLen(^uint64(0)) {
+		return nil, fmt.Errorf("presents: NewFixedWidth: width must be between 1 and %d, not %d",
+			a.encodedLen(^uint64(0)), width)
+	}
+	// find the largest power of two no larger than len(a)^width
+	max := uint64(1)
+	overflow := false
+	for i := 0; i < width && !overflow; i++ {
+		hi, lo := bits.Mul64(max, uint64(len(a)))
+		max, overflow = lo, hi != 0
+	}
+	b := 64
+	if !overflow {
+		b = bits.Len64(max) - 1
+	}
+	return &FixedWidth{alphabet: a, width: width, bits: b}, nil
+}
+
+func (f *FixedWidth) Bits() int {
+	return f.bits
+}
+
+// Encode converts n to a string of Width digits. The result is longer if n is too large to fit.
+func (f *FixedWidth) Encode(n uint64) string {
+	s := f.alphabet.Encode(n)
+	if len(s) < f.width {
+		s += strings.Repeat(string(f.alphabet[0]), f.width-len(s))
+	}
+	return s
+}
+
+// Decode converts a string of Width digits back to an integer.
+// It returns ErrLength if s has the wrong length, and ErrOverflow if the result is not below 2^Bits().
+func (f *FixedWidth) Decode(s string) (uint64, error) {
+	if len(s) != f.width {
+		return 0, ErrLength
+	}
+	n, err := f.alphabet.Decode(s)
+	if err != nil {
+		return 0, err
+	}
+	if f.bits < 64 && n>>uint(f.bits) != 0 {
+		return 0, ErrOverflow
+	}
+	return n, nil
+}
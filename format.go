@@ -0,0 +1,99 @@
+package presents
+
+import (
+	"strings"
+	"unicode"
+)
+
+// DefaultSeparator separates groups of characters when Options.GroupSize is set but Options.Separator is not.
+const DefaultSeparator = '-'
+
+// Grouping is a Decorator which splits strings into groups of Size characters separated by Separator,
+// or DefaultSeparator if Separator is zero, as in "7HQ2-K9XM-3PD".
+// Undecorate removes separators and whitespace anywhere in the string,
+// so Pipeline.Build rejects a Grouping if the encoded string may contain either of them,
+// or if the Encoder is not one of the built-in ones, whose characters are known.
+type Grouping struct {
+	Size      int
+	Separator rune
+}
+
+func (g Grouping) separator() rune {
+	if g.Separator == 0 {
+		return DefaultSeparator
+	}
+	return g.Separator
+}
+
+func (g Grouping) Decorate(s string) string {
+	if len(s) <= g.Size {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i += g.Size {
+		if i > 0 {
+			b.WriteRune(g.separator())
+		}
+		end := i + g.Size
+		if end > len(s) {
+			end = len(s)
+		}
+		b.WriteString(s[i:end])
+	}
+	return b.String()
+}
+
+func (g Grouping) Undecorate(s string) (string, error) {
+	return strings.Map(func(r rune) rune {
+		if g.ignored(r) {
+			return -1
+		}
+		return r
+	}, s), nil
+}
+
+// ignored reports whether Undecorate removes r.
+func (g Grouping) ignored(r rune) bool {
+	return r == g.separator() || unicode.IsSpace(r)
+}
+
+// decoratedPos converts a byte offset into the output of Undecorate to a byte offset into s.
+func (g Grouping) decoratedPos(s string, pos int) int {
+	for i, r := range s {
+		if g.ignored(r) {
+			continue
+		}
+		if pos == 0 {
+			return i
+		}
+		pos -= len(string(r))
+	}
+	return len(s)
+}
+
+// padding is the Decorator returned by Padding.
+// Its width and pad character are filled in from the Alphabet by Pipeline.Build.
+type padding struct {
+	width int
+	char  byte
+}
+
+// Padding returns a Decorator which pads strings with the first character of the alphabet
+// to the length of the longest string the alphabet can produce, so that all strings have the same length.
+// Strings which avoid a blocklist have an extra tweak digit, so they are one character longer.
+// It can only be used with an Alphabet encoder, which ignores the padding since it is made of trailing zero digits.
+// Padding should come before Grouping, so that the groups line up.
+func Padding() Decorator {
+	return padding{}
+}
+
+func (p padding) Decorate(s string) string {
+	if len(s) < p.width {
+		s += strings.Repeat(string(p.char), p.width-len(s))
+	}
+	return s
+}
+
+func (p padding) Undecorate(s string) (string, error) {
+	return s, nil
+}
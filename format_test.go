@@ -0,0 +1,107 @@
+package presents_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yi-jiayu/presents"
+)
+
+func TestPresents_Wrap_format(t *testing.T) {
+	key := make([]byte, 10)
+	testCases := []struct {
+		name     string
+		options  *presents.Options
+		n        uint64
+		expected string
+	}{
+		{"default", nil, 1213486160, "90NyXHLckhA"},
+		{"grouped", &presents.Options{GroupSize: 4}, 1213486160, "90Ny-XHLc-khA"},
+		{"separator", &presents.Options{GroupSize: 3, Separator: ' '}, 1213486160, "90N yXH Lck hA"},
+		{"unicode separator", &presents.Options{GroupSize: 5, Separator: '·'}, 1213486160, "90NyX·HLckh·A"},
+		{"padded", &presents.Options{Padding: true}, 0, ""},
+		{"padded and grouped", &presents.Options{Padding: true, GroupSize: 4}, 0, ""},
+	}
+	plain, err := presents.New(key, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := presents.New(key, tc.options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s := p.Wrap(tc.n)
+			if tc.expected != "" {
+				assert.Equal(t, tc.expected, s)
+			}
+			n, err := p.Unwrap(s)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.n, n)
+		})
+	}
+
+	t.Run("padding", func(t *testing.T) {
+		p, err := presents.New(key, &presents.Options{Padding: true, GroupSize: 4})
+		if err != nil {
+			t.Fatal(err)
+		}
+		// find an ID whose string is shorter than usual
+		var n uint64
+		for len(plain.Wrap(n)) == 11 {
+			n++
+		}
+		short := plain.Wrap(n)
+		s := p.Wrap(n)
+		assert.Len(t, s, 13)
+		assert.Equal(t, short+strings.Repeat("0", 11-len(short)), strings.ReplaceAll(s, "-", ""))
+		for i := uint64(0); i < 100; i++ {
+			assert.Len(t, p.Wrap(i), 13)
+		}
+		dst := make([]string, 100)
+		src := make([]uint64, 100)
+		for i := range src {
+			src[i] = uint64(i)
+		}
+		p.WrapBatch(dst, src)
+		for i, n := range src {
+			assert.Equal(t, p.Wrap(n), dst[i])
+		}
+	})
+}
+
+func TestPresents_Unwrap_format(t *testing.T) {
+	p, err := presents.New(make([]byte, 10), &presents.Options{GroupSize: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"90Ny-XHLc-khA", "90NyXHLckhA", " 90Ny XHLc khA\n", "9-0-N-y-X-H-L-c-k-h-A", "90Ny\tXHLc-khA"} {
+		n, err := p.Unwrap(s)
+		assert.NoError(t, err, "%q", s)
+		assert.Equal(t, uint64(1213486160), n, "%q", s)
+	}
+	_, err = p.Unwrap("90Ny-XH_c-khA")
+	assert.Equal(t, &presents.InvalidCharError{Pos: 7, Char: '_'}, err)
+	_, err = p.Unwrap("90Ny-XHé-khA")
+	assert.Equal(t, &presents.InvalidCharError{Pos: 7, Char: 'é'}, err)
+	_, err = p.Unwrap("--")
+	assert.Equal(t, presents.ErrLength, err)
+
+	plain, err := presents.New(make([]byte, 10), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = plain.Unwrap("90Ny-XHLc-khA")
+	assert.Equal(t, &presents.InvalidCharError{Pos: 4, Char: '-'}, err)
+}
+
+func TestNew_format(t *testing.T) {
+	_, err := presents.New(make([]byte, 10), &presents.Options{GroupSize: 4, Separator: 'A'})
+	assert.EqualError(t, err, "presents: separator 'A' is in the alphabet")
+	_, err = presents.New(make([]byte, 10), &presents.Options{GroupSize: -1})
+	assert.EqualError(t, err, "presents: GroupSize cannot be negative")
+	_, err = presents.New(make([]byte, 10), &presents.Options{Alphabet: "0123456789-", GroupSize: 4})
+	assert.EqualError(t, err, `presents: separator '-' is in the alphabet`)
+}
@@ -0,0 +1,129 @@
+package presents
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+	"math/bits"
+)
+
+// giftRounds is the number of rounds in GIFT-64/128.
+const giftRounds = 28
+
+// GIFT-64 substitution and bit permutation tables.
+var (
+	giftSBox    = [16]uint64{1, 0xA, 4, 0xC, 6, 0xF, 3, 9, 2, 0xD, 0xB, 7, 5, 0, 8, 0xE}
+	giftSBoxInv = invertSBox(giftSBox)
+	giftP       = [64]uint{
+		0, 17, 34, 51, 48, 1, 18, 35, 32, 49, 2, 19, 16, 33, 50, 3,
+		4, 21, 38, 55, 52, 5, 22, 39, 36, 53, 6, 23, 20, 37, 54, 7,
+		8, 25, 42, 59, 56, 9, 26, 43, 40, 57, 10, 27, 24, 41, 58, 11,
+		12, 29, 46, 63, 60, 13, 30, 47, 44, 61, 14, 31, 28, 45, 62, 15,
+	}
+)
+
+// giftBlock implements the GIFT-64/128 block cipher as defined by Banik et al. in
+// GIFT: A Small Present (https://eprint.iacr.org/2017/622).
+//
+// Blocks and keys are big-endian, so the first byte of the key holds the most significant bits of k7.
+type giftBlock struct {
+	// roundKeys holds the round key and round constant of each round, already spread over the state bits.
+	roundKeys [giftRounds]uint64
+}
+
+// newGIFTCipher returns a GIFT-64/128 cipher.Block for a 16-byte key.
+func newGIFTCipher(key []byte) (cipher.Block, error) {
+	if len(key) != 16 {
+		return nil, &KeySizeError{Cipher: "gift64/128", Size: len(key), Valid: []int{16}}
+	}
+	// k[i] is the 16-bit key word k_i
+	var k [8]uint16
+	for i := range k {
+		k[i] = binary.BigEndian.Uint16(key[14-2*i:])
+	}
+	var b giftBlock
+	var c uint64
+	for r := range b.roundKeys {
+		u, v := uint64(k[1]), uint64(k[0])
+		var rk uint64
+		for i := uint(0); i < 16; i++ {
+			rk |= (u>>i&1)<<(4*i+1) | (v>>i&1)<<(4*i)
+		}
+		c = (c<<1 | (c>>5^c>>4^1)&1) & 0x3F
+		rk ^= 1 << 63
+		for i := uint(0); i < 6; i++ {
+			rk ^= (c >> i & 1) << (4*i + 3)
+		}
+		b.roundKeys[r] = rk
+		k = [8]uint16{k[2], k[3], k[4], k[5], k[6], k[7], bits.RotateLeft16(k[0], -12), bits.RotateLeft16(k[1], -2)}
+	}
+	return &b, nil
+}
+
+func (b *giftBlock) BlockSize() int {
+	return 8
+}
+
+func (b *giftBlock) Encrypt(dst, src []byte) {
+	if len(src) < 8 {
+		panic("presents: gift: input not full block")
+	}
+	if len(dst) < 8 {
+		panic("presents: gift: output not full block")
+	}
+	s := binary.BigEndian.Uint64(src)
+	for _, rk := range b.roundKeys {
+		s = substitute(s, &giftSBox)
+		var t uint64
+		for i, pi := range giftP {
+			t |= (s >> uint(i) & 1) << pi
+		}
+		s = t ^ rk
+	}
+	binary.BigEndian.PutUint64(dst, s)
+}
+
+func (b *giftBlock) Decrypt(dst, src []byte) {
+	if len(src) < 8 {
+		panic("presents: gift: input not full block")
+	}
+	if len(dst) < 8 {
+		panic("presents: gift: output not full block")
+	}
+	s := binary.BigEndian.Uint64(src)
+	for r := giftRounds - 1; r >= 0; r-- {
+		s ^= b.roundKeys[r]
+		var t uint64
+		for i, pi := range giftP {
+			t |= (s >> pi & 1) << uint(i)
+		}
+		s = substitute(t, &giftSBoxInv)
+	}
+	binary.BigEndian.PutUint64(dst, s)
+}
+
+// substitute applies the 4-bit S-box sBox to each nibble of s.
+func substitute(s uint64, sBox *[16]uint64) (result uint64) {
+	for i := uint(0); i < 64; i += 4 {
+		result |= sBox[s>>i&0xF] << i
+	}
+	return
+}
+
+// invertSBox returns the inverse of the 4-bit S-box sBox.
+func invertSBox(sBox [16]uint64) (inv [16]uint64) {
+	for x, y := range sBox {
+		inv[y] = uint64(x)
+	}
+	return
+}
+
+// NewGIFT creates a new Presents struct using GIFT-64/128 instead of PRESENT.
+// The key should be 16 bytes long.
+// The options are as for New.
+func NewGIFT(key []byte, options ...Option) (*Presents, error) {
+	c, err := newGIFTCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return newWithKey(c, key, options)
+}
@@ -0,0 +1,16 @@
+package presents
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGIFT(t *testing.T) {
+	testKnownAnswers(t, newGIFTCipher, knownAnswers["gift64/128"])
+}
+
+func TestNewGIFTCipher(t *testing.T) {
+	_, err := newGIFTCipher(make([]byte, 10))
+	assert.Equal(t, &KeySizeError{Cipher: "gift64/128", Size: 10, Valid: []int{16}}, err)
+}
@@ -0,0 +1,231 @@
+package presents
+
+import (
+	"errors"
+	"math"
+	"math/bits"
+	"strings"
+)
+
+// Default hashids parameters.
+const (
+	hashidsDefaultAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ1234567890"
+	hashidsDefaultSeps     = "cfhistuCFHISTU"
+	hashidsMinAlphabetLen  = 16
+	hashidsSepDiv          = 3.5
+	hashidsGuardDiv        = 12
+)
+
+// Hashids decodes strings produced by hashids (https://hashids.org/),
+// so that legacy IDs can still be read while migrating to presents.
+type Hashids struct {
+	salt      string
+	minLength int
+	alphabet  string
+	seps      string
+	guards    string
+}
+
+// NewHashids returns a hashids decoder with the same parameters as the encoder that produced the IDs.
+// If alphabet is the empty string, the default hashids alphabet is used.
+func NewHashids(salt string, minLength int, alphabet string) (*Hashids, error) {
+	if alphabet == "" {
+		alphabet = hashidsDefaultAlphabet
+	}
+	var uniq []byte
+	for i := 0; i < len(alphabet); i++ {
+		if strings.IndexByte(string(uniq), alphabet[i]) == -1 {
+			uniq = append(uniq, alphabet[i])
+		}
+	}
+	alphabet = string(uniq)
+	if len(alphabet) < hashidsMinAlphabetLen {
+		return nil, errors.New("presents: NewHashids: alphabet must contain at least 16 unique characters")
+	}
+	if strings.IndexByte(alphabet, ' ') != -1 {
+		return nil, errors.New("presents: NewHashids: alphabet cannot contain spaces")
+	}
+
+	// separators are the default separators which appear in the alphabet, and are removed from it
+	var seps []byte
+	for i := 0; i < len(hashidsDefaultSeps); i++ {
+		if strings.IndexByte(alphabet, hashidsDefaultSeps[i]) != -1 {
+			seps = append(seps, hashidsDefaultSeps[i])
+		}
+	}
+	alphabet = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(string(seps), r) {
+			return -1
+		}
+		return r
+	}, alphabet)
+	seps = hashidsShuffle(seps, salt)
+
+	if len(seps) == 0 || float64(len(alphabet))/float64(len(seps)) > hashidsSepDiv {
+		sepsLen := int(math.Ceil(float64(len(alphabet)) / hashidsSepDiv))
+		if sepsLen == 1 {
+			sepsLen++
+		}
+		if sepsLen > len(seps) {
+			diff := sepsLen - len(seps)
+			seps = append(seps, alphabet[:diff]...)
+			alphabet = alphabet[diff:]
+		} else {
+			seps = seps[:sepsLen]
+		}
+	}
+
+	shuffled := hashidsShuffle([]byte(alphabet), salt)
+	guardCount := int(math.Ceil(float64(len(shuffled)) / hashidsGuardDiv))
+	var guards []byte
+	if len(shuffled) < 3 {
+		guards, seps = seps[:guardCount], seps[guardCount:]
+	} else {
+		guards, shuffled = shuffled[:guardCount], shuffled[guardCount:]
+	}
+	return &Hashids{
+		salt:      salt,
+		minLength: minLength,
+		alphabet:  string(shuffled),
+		seps:      string(seps),
+		guards:    string(guards),
+	}, nil
+}
+
+// hashidsShuffle permutes alphabet in place using salt, and returns it.
+func hashidsShuffle(alphabet []byte, salt string) []byte {
+	if salt == "" {
+		return alphabet
+	}
+	for i, v, p := len(alphabet)-1, 0, 0; i > 0; i, v = i-1, v+1 {
+		v %= len(salt)
+		n := int(salt[v])
+		p += n
+		j := (n + v + p) % i
+		alphabet[i], alphabet[j] = alphabet[j], alphabet[i]
+	}
+	return alphabet
+}
+
+// Decode returns the numbers encoded in s.
+// It returns an error if s was not produced by a hashids encoder with the same parameters.
+func (h *Hashids) Decode(s string) ([]uint64, error) {
+	invalid := errors.New("presents: hashids: invalid input")
+	// guards may surround the section containing the numbers
+	breakdown := splitAny(s, h.guards)
+	i := 0
+	if len(breakdown) == 2 || len(breakdown) == 3 {
+		i = 1
+	}
+	body := breakdown[i]
+	if body == "" {
+		return nil, invalid
+	}
+	lottery := body[0]
+	alphabet := []byte(h.alphabet)
+	var numbers []uint64
+	for _, sub := range splitAny(body[1:], h.seps) {
+		buffer := string(lottery) + h.salt + string(alphabet)
+		alphabet = hashidsShuffle(alphabet, buffer[:len(alphabet)])
+		n, ok := parseDigits(sub, alphabet)
+		if !ok {
+			return nil, invalid
+		}
+		numbers = append(numbers, n)
+	}
+	if h.encode(numbers) != s {
+		return nil, invalid
+	}
+	return numbers, nil
+}
+
+// encode implements the hashids encoding algorithm. It is used to check the results of Decode.
+func (h *Hashids) encode(numbers []uint64) string {
+	if len(numbers) == 0 {
+		return ""
+	}
+	alphabet := []byte(h.alphabet)
+	var id uint64
+	for i, n := range numbers {
+		id += n % uint64(i+100)
+	}
+	lottery := alphabet[id%uint64(len(alphabet))]
+	ret := []byte{lottery}
+	for i, n := range numbers {
+		buffer := string(lottery) + h.salt + string(alphabet)
+		alphabet = hashidsShuffle(alphabet, buffer[:len(alphabet)])
+		last := hashidsToAlphabet(n, alphabet)
+		ret = append(ret, last...)
+		if i+1 < len(numbers) {
+			n %= uint64(last[0]) + uint64(i)
+			ret = append(ret, h.seps[n%uint64(len(h.seps))])
+		}
+	}
+	if len(ret) < h.minLength {
+		guard := h.guards[(id+uint64(ret[0]))%uint64(len(h.guards))]
+		ret = append([]byte{guard}, ret...)
+		if len(ret) < h.minLength {
+			guard := h.guards[(id+uint64(ret[2]))%uint64(len(h.guards))]
+			ret = append(ret, guard)
+		}
+	}
+	half := len(alphabet) / 2
+	for len(ret) < h.minLength {
+		alphabet = hashidsShuffle(alphabet, string(alphabet))
+		padded := append(append(append([]byte(nil), alphabet[half:]...), ret...), alphabet[:half]...)
+		ret = padded
+		if excess := len(ret) - h.minLength; excess > 0 {
+			ret = ret[excess/2 : excess/2+h.minLength]
+		}
+	}
+	return string(ret)
+}
+
+func hashidsToAlphabet(n uint64, alphabet []byte) []byte {
+	base := uint64(len(alphabet))
+	var s []byte
+	for {
+		s = append([]byte{alphabet[n%base]}, s...)
+		n /= base
+		if n == 0 {
+			return s
+		}
+	}
+}
+
+// parseDigits converts s to a number, most significant digit first, using the bytes of digits as the digits.
+// It reports false if s is empty, contains characters outside digits or overflows a uint64.
+func parseDigits(s string, digits []byte) (uint64, bool) {
+	if s == "" {
+		return 0, false
+	}
+	base := uint64(len(digits))
+	var n uint64
+	for i := 0; i < len(s); i++ {
+		x := strings.IndexByte(string(digits), s[i])
+		if x == -1 {
+			return 0, false
+		}
+		hi, lo := bits.Mul64(n, base)
+		var carry uint64
+		lo, carry = bits.Add64(lo, uint64(x), 0)
+		if hi != 0 || carry != 0 {
+			return 0, false
+		}
+		n = lo
+	}
+	return n, true
+}
+
+// splitAny splits s around each occurrence of any byte in seps, keeping empty fields.
+func splitAny(s, seps string) []string {
+	var fields []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		if strings.IndexByte(seps, s[i]) != -1 {
+			fields = append(fields, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(fields, s[start:])
+}
@@ -0,0 +1,64 @@
+package presents
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashids_Decode(t *testing.T) {
+	vectors := []struct {
+		salt      string
+		minLength int
+		alphabet  string
+		id        string
+		numbers   []uint64
+	}{
+		{"this is my salt", 0, "", "NkK9", []uint64{12345}},
+		{"this is my salt", 0, "", "aBMswoO2UB3Sj", []uint64{683, 94108, 123, 5}},
+		{"this is my salt", 8, "", "gB0NV05e", []uint64{1}},
+		{"", 0, "", "o2fXhV", []uint64{1, 2, 3}},
+	}
+	for _, v := range vectors {
+		h, err := NewHashids(v.salt, v.minLength, v.alphabet)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, v.id, h.encode(v.numbers))
+		numbers, err := h.Decode(v.id)
+		assert.NoError(t, err)
+		assert.Equal(t, v.numbers, numbers)
+	}
+}
+
+func TestHashids_roundTrip(t *testing.T) {
+	h, err := NewHashids("salt", 12, "0123456789abcdefghij")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, numbers := range [][]uint64{{0}, {1}, {1 << 63}, {18446744073709551615}, {5, 0, 7}} {
+		id := h.encode(numbers)
+		assert.True(t, len(id) >= 12)
+		decoded, err := h.Decode(id)
+		assert.NoError(t, err)
+		assert.Equal(t, numbers, decoded)
+	}
+}
+
+func TestHashids_Decode_invalid(t *testing.T) {
+	h, err := NewHashids("this is my salt", 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"", "NkK8", "NkK9x", "!!!!"} {
+		_, err := h.Decode(id)
+		assert.Error(t, err, id)
+	}
+}
+
+func TestNewHashids(t *testing.T) {
+	_, err := NewHashids("", 0, "abcdefghij")
+	assert.EqualError(t, err, "presents: NewHashids: alphabet must contain at least 16 unique characters")
+	_, err = NewHashids("", 0, "abcdefghijklmnop qrstuvwxyz")
+	assert.EqualError(t, err, "presents: NewHashids: alphabet cannot contain spaces")
+}
@@ -0,0 +1,83 @@
+package presents
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// DefaultIterations is the number of PBKDF2 iterations used by NewFromPassphrase when none is specified.
+const DefaultIterations = 600000
+
+// PassphraseOptions controls how NewFromPassphrase derives a key from a passphrase.
+type PassphraseOptions struct {
+	// Cipher is the name of the cipher to use, as accepted by NewFromSpec.
+	// It defaults to present-128.
+	Cipher string
+	// Iterations is the PBKDF2 iteration count. It defaults to DefaultIterations.
+	Iterations int
+	// ShuffleAlphabet shuffles the alphabet as KeyedShuffle does, using a secret derived from the passphrase and salt.
+	// It cannot be combined with options which also shuffle the alphabet.
+	ShuffleAlphabet bool
+}
+
+// NewFromPassphrase creates a new Presents struct with a key derived from a passphrase and salt
+// using PBKDF2-HMAC-SHA256, as a replacement for the salt string used by hashids.
+// The derivation is deterministic, so the same passphrase, salt and options always give the same codec.
+//
+// If the cipher accepts several key sizes, the largest is used.
+// The options are as for New.
+func NewFromPassphrase(passphrase, salt string, kdfOptions *PassphraseOptions, options ...Option) (*Presents, error) {
+	var kdf PassphraseOptions
+	if kdfOptions != nil {
+		kdf = *kdfOptions
+	}
+	if kdf.Cipher == "" {
+		kdf.Cipher = "present-128"
+	}
+	if kdf.Iterations == 0 {
+		kdf.Iterations = DefaultIterations
+	}
+	if kdf.Iterations < 0 {
+		return nil, errors.New("presents: NewFromPassphrase: iterations must be positive")
+	}
+	keySizes := cipherKeySizes(kdf.Cipher)
+	if keySizes == nil {
+		return nil, fmt.Errorf("%w %q", ErrUnknownCipher, kdf.Cipher)
+	}
+	keySize := keySizes[len(keySizes)-1]
+	derived := pbkdf2SHA256([]byte(passphrase), []byte(salt), kdf.Iterations, keySize+shuffleKeySize)
+	if kdf.ShuffleAlphabet {
+		options = append(options[:len(options):len(options)], passphraseShuffle(derived[keySize:]))
+	}
+	return NewFromSpec(kdf.Cipher, derived[:keySize], options...)
+}
+
+// pbkdf2SHA256 implements PBKDF2 as defined in RFC 8018 using HMAC-SHA256 as the pseudorandom function.
+func pbkdf2SHA256(password, salt []byte, iterations, keyLen int) []byte {
+	prf := hmac.New(sha256.New, password)
+	var dk []byte
+	u := make([]byte, sha256.Size)
+	t := make([]byte, sha256.Size)
+	for block := uint32(1); len(dk) < keyLen; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		var ctr [4]byte
+		binary.BigEndian.PutUint32(ctr[:], block)
+		prf.Write(ctr[:])
+		u = prf.Sum(u[:0])
+		copy(t, u)
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		dk = append(dk, t...)
+	}
+	return dk[:keyLen]
+}
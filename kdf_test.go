@@ -0,0 +1,90 @@
+package presents
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPBKDF2SHA256(t *testing.T) {
+	vectors := []struct {
+		password, salt string
+		iterations     int
+		expected       string
+	}{
+		{"password", "salt", 1, "120fb6cffcf8b32c43e7225256c4f837a86548c92ccc35480805987cb70be17b"},
+		{"password", "salt", 2, "ae4d0c95af6b46d32d0adff928f06dd02a303f8ef3c251dfd6e2d85a95474c43"},
+		{"password", "salt", 4096, "c5e478d59288c841aa530db6845c4c8d962893a001ce4e11a4963873aa98134a"},
+	}
+	for _, v := range vectors {
+		dk := pbkdf2SHA256([]byte(v.password), []byte(v.salt), v.iterations, 32)
+		assert.Equal(t, v.expected, hex.EncodeToString(dk))
+	}
+	// output longer than one block
+	dk := pbkdf2SHA256([]byte("password"), []byte("salt"), 2, 40)
+	assert.Equal(t, "ae4d0c95af6b46d32d0adff928f06dd02a303f8ef3c251dfd6e2d85a95474c43", hex.EncodeToString(dk[:32]))
+	assert.Len(t, dk, 40)
+}
+
+func TestNewFromPassphrase(t *testing.T) {
+	kdf := &PassphraseOptions{Iterations: 1000}
+	t.Run("deterministic", func(t *testing.T) {
+		p, err := NewFromPassphrase("correct horse", "this is my salt", kdf, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		q, err := NewFromPassphrase("correct horse", "this is my salt", kdf, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, p.Wrap(1213486160), q.Wrap(1213486160))
+		assert.Equal(t, DefaultAlphabet, p.alphabet)
+		key := pbkdf2SHA256([]byte("correct horse"), []byte("this is my salt"), 1000, 16)
+		expected, err := New(key, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, expected.Wrap(1213486160), p.Wrap(1213486160))
+	})
+	t.Run("different salt", func(t *testing.T) {
+		p, err := NewFromPassphrase("correct horse", "salt 1", kdf, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		q, err := NewFromPassphrase("correct horse", "salt 2", kdf, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.NotEqual(t, p.Wrap(1213486160), q.Wrap(1213486160))
+	})
+	t.Run("shuffled alphabet", func(t *testing.T) {
+		p, err := NewFromPassphrase("correct horse", "this is my salt", &PassphraseOptions{
+			Cipher:          "speck64/128",
+			Iterations:      1000,
+			ShuffleAlphabet: true,
+		}, &Options{Alphabet: "0123456789abcdef"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.NotEqual(t, Alphabet("0123456789abcdef"), p.alphabet)
+		assert.ElementsMatch(t, []byte("0123456789abcdef"), []byte(p.alphabet))
+		derived := pbkdf2SHA256([]byte("correct horse"), []byte("this is my salt"), 1000, 16+shuffleKeySize)
+		assert.Equal(t, Alphabet("0123456789abcdef").KeyedShuffle(derived[16:]), p.alphabet)
+		n, err := p.Unwrap(p.Wrap(1213486160))
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(1213486160), n)
+	})
+	t.Run("shuffled alphabet conflicts", func(t *testing.T) {
+		kdf := &PassphraseOptions{Iterations: 1, ShuffleAlphabet: true}
+		for _, o := range []Option{WithShuffle(1), WithKeyedShuffle(), &Options{Shuffle: true, Seed: 1}} {
+			_, err := NewFromPassphrase("correct horse", "salt", kdf, o)
+			assert.EqualError(t, err, "presents: NewFromPassphrase: ShuffleAlphabet cannot be combined with another shuffle")
+		}
+	})
+	t.Run("unknown cipher", func(t *testing.T) {
+		_, err := NewFromPassphrase("correct horse", "salt", &PassphraseOptions{Cipher: "rot13"}, nil)
+		assert.True(t, errors.Is(err, ErrUnknownCipher))
+	})
+}
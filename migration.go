@@ -0,0 +1,105 @@
+package presents
+
+import (
+	"fmt"
+)
+
+// LegacyDecoder decodes IDs produced by another library, such as Hashids or Sqids.
+type LegacyDecoder interface {
+	Decode(s string) ([]uint64, error)
+}
+
+// Source identifies how a MigrationCodec decoded a string.
+type Source int
+
+// Sources reported by MigrationCodec.Unwrap.
+const (
+	SourceNone Source = iota
+	SourcePresents
+	SourceLegacy
+)
+
+func (s Source) String() string {
+	switch s {
+	case SourcePresents:
+		return "presents"
+	case SourceLegacy:
+		return "legacy"
+	default:
+		return "none"
+	}
+}
+
+// MigrationCodec unwraps both presents strings and legacy IDs during a migration to presents.
+// New IDs are always wrapped using presents.
+//
+// Since every string made of alphabet characters unwraps to some integer,
+// a string is only accepted as a presents string without consulting the legacy decoder
+// if Wrap reproduces it exactly and it is at least MinLength characters long.
+// Shorter strings are offered to the legacy decoder first,
+// and only unwrapped using presents if the legacy decoder rejects them.
+// A few short presents strings are also valid legacy IDs, usually of huge numbers;
+// setting MaxLegacyID makes those come from presents instead.
+type MigrationCodec struct {
+	presents *Presents
+	legacy   LegacyDecoder
+
+	// MinLength is the length from which strings are assumed to come from presents.
+	// NewMigrationCodec sets it to one less than the length of the longest presents string,
+	// which all but about 0.1% of presents strings reach with a 62-character alphabet.
+	MinLength int
+
+	// MaxLegacyID, if not zero, is the largest number a legacy ID can decode to,
+	// such as the largest ID issued before the migration.
+	// Strings which decode to larger numbers are not accepted as legacy IDs.
+	MaxLegacyID uint64
+}
+
+// NewMigrationCodec returns a MigrationCodec which wraps using p and falls back to legacy when unwrapping.
+func NewMigrationCodec(p *Presents, legacy LegacyDecoder) *MigrationCodec {
+	// the longest string is the one for the largest value passed to the Encoder
+	bits := p.permutation.Bits()
+	if p.integrity != nil {
+		bits += p.integrity.Bits()
+	}
+	return &MigrationCodec{
+		presents:  p,
+		legacy:    legacy,
+		MinLength: len(p.decorate(p.encode(mask(bits)))) - 1,
+	}
+}
+
+// Wrap converts an unsigned 64-bit integer to a string using presents.
+func (m *MigrationCodec) Wrap(n uint64) string {
+	return m.presents.Wrap(n)
+}
+
+// Unwrap converts s back to an unsigned 64-bit integer,
+// reporting whether it was decoded by presents or by the legacy decoder.
+// Legacy strings must encode exactly one number.
+func (m *MigrationCodec) Unwrap(s string) (uint64, Source, error) {
+	n, err := m.presents.Unwrap(s)
+	// re-encode without Wrap, so that the Observer only sees the call to Unwrap
+	canonical := err == nil && m.presents.wrap(n) == s
+	if canonical && len(s) >= m.MinLength {
+		return n, SourcePresents, nil
+	}
+	numbers, legacyErr := m.legacy.Decode(s)
+	if legacyErr == nil {
+		switch {
+		case len(numbers) != 1:
+			legacyErr = fmt.Errorf("presents: legacy ID contains %d numbers", len(numbers))
+		case m.MaxLegacyID != 0 && numbers[0] > m.MaxLegacyID:
+			legacyErr = fmt.Errorf("presents: legacy ID %d exceeds MaxLegacyID", numbers[0])
+		default:
+			return numbers[0], SourceLegacy, nil
+		}
+	}
+	if canonical {
+		return n, SourcePresents, nil
+	}
+	if err == nil {
+		err = ErrNonCanonical
+	}
+	return 0, SourceNone, fmt.Errorf("%w; legacy: %v", err, legacyErr)
+}
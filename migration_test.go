@@ -0,0 +1,117 @@
+package presents_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yi-jiayu/presents"
+)
+
+func TestMigrationCodec_Unwrap(t *testing.T) {
+	p, err := presents.New(make([]byte, 10), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, err := presents.NewHashids("this is my salt", 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := presents.NewMigrationCodec(p, h)
+	assert.Equal(t, 10, m.MinLength)
+	assert.Equal(t, p.Wrap(1213486160), m.Wrap(1213486160))
+
+	t.Run("presents", func(t *testing.T) {
+		n, source, err := m.Unwrap("90NyXHLckhA")
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(1213486160), n)
+		assert.Equal(t, presents.SourcePresents, source)
+	})
+	t.Run("hashids", func(t *testing.T) {
+		n, source, err := m.Unwrap("NkK9")
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(12345), n)
+		assert.Equal(t, presents.SourceLegacy, source)
+		assert.Equal(t, "legacy", source.String())
+	})
+	t.Run("short presents string", func(t *testing.T) {
+		// "NkK8" is not a valid hashid, but is a canonical presents string
+		n, err := p.Unwrap("NkK8")
+		if err != nil {
+			t.Fatal(err)
+		}
+		m, source, err := m.Unwrap("NkK8")
+		assert.NoError(t, err)
+		assert.Equal(t, n, m)
+		assert.Equal(t, presents.SourcePresents, source)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		_, source, err := m.Unwrap("!!")
+		assert.EqualError(t, err, "presents: invalid character '!' at position 0; legacy: presents: hashids: invalid input")
+		assert.True(t, errors.Is(err, presents.ErrInvalidChar))
+		assert.Equal(t, presents.SourceNone, source)
+	})
+}
+
+func TestMigrationCodec_sqids(t *testing.T) {
+	p, err := presents.New(make([]byte, 10), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, err := presents.NewSqids("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := presents.NewMigrationCodec(p, q)
+	n, source, err := m.Unwrap("Uk")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), n)
+	assert.Equal(t, presents.SourceLegacy, source)
+	// legacy IDs containing several numbers are not accepted, so this short string falls back to presents
+	_, source, err = m.Unwrap("86Rf07")
+	assert.NoError(t, err)
+	assert.Equal(t, presents.SourcePresents, source)
+}
+
+// Sqids used to accept almost any string made of its alphabet,
+// so short presents strings were read as legacy IDs, such as "uzsKqyW14" for 2573.
+func TestMigrationCodec_sqidsSweep(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping sweep in short mode")
+	}
+	p, err := presents.New(make([]byte, 10), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, err := presents.NewSqids("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := presents.NewMigrationCodec(p, q)
+	strict := presents.NewMigrationCodec(p, q)
+	strict.MaxLegacyID = 1 << 32
+	var ambiguous []string
+	for n := uint64(0); n < 50000; n++ {
+		s := m.Wrap(n)
+		actual, source, err := m.Unwrap(s)
+		if err != nil || actual != n || source != presents.SourcePresents {
+			// only strings which the Sqids encoder would also produce are ambiguous
+			ambiguous = append(ambiguous, s)
+			numbers, err := q.Decode(s)
+			assert.NoError(t, err, s)
+			assert.Equal(t, []uint64{actual}, numbers, s)
+		}
+		actual, source, err = strict.Unwrap(s)
+		if err != nil || actual != n || source != presents.SourcePresents {
+			t.Fatalf("%d wraps to %q, which unwraps to %d from %s (error %v)", n, s, actual, source, err)
+		}
+	}
+
+	assert.Equal(t, []string{"kCrn3IFxl"}, ambiguous)
+
+	// legacy IDs below MaxLegacyID are still accepted
+	n, source, err := strict.Unwrap("Uk")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), n)
+	assert.Equal(t, presents.SourceLegacy, source)
+}
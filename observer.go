@@ -0,0 +1,173 @@
+package presents
+
+import (
+	"errors"
+	"expvar"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// ErrorKind classifies the errors returned by Unwrap.
+type ErrorKind int
+
+const (
+	// KindOther is any error not covered by another kind.
+	KindOther ErrorKind = iota
+	// KindLength matches ErrLength.
+	KindLength
+	// KindInvalidChar matches ErrInvalidChar.
+	KindInvalidChar
+	// KindOverflow matches ErrOverflow.
+	KindOverflow
+	// KindChecksum matches ErrChecksum.
+	KindChecksum
+	// KindNonCanonical matches ErrNonCanonical.
+	KindNonCanonical
+	// KindRejected matches ErrRejected.
+	KindRejected
+)
+
+var errorKinds = []struct {
+	err  error
+	name string
+}{
+	KindOther:        {nil, "other"},
+	KindLength:       {ErrLength, "length"},
+	KindInvalidChar:  {ErrInvalidChar, "invalid_char"},
+	KindOverflow:     {ErrOverflow, "overflow"},
+	KindChecksum:     {ErrChecksum, "checksum"},
+	KindNonCanonical: {ErrNonCanonical, "non_canonical"},
+	KindRejected:     {ErrRejected, "rejected"},
+}
+
+// ErrorKindOf returns the kind of err, using errors.Is to match it against the sentinel errors.
+func ErrorKindOf(err error) ErrorKind {
+	for kind, k := range errorKinds {
+		if k.err != nil && errors.Is(err, k.err) {
+			return ErrorKind(kind)
+		}
+	}
+	return KindOther
+}
+
+func (k ErrorKind) String() string {
+	if k < 0 || int(k) >= len(errorKinds) {
+		return "ErrorKind(" + strconv.Itoa(int(k)) + ")"
+	}
+	return errorKinds[k].name
+}
+
+// Observer is notified of the outcome of each call to Wrap and Unwrap on a Presents, for example to record metrics.
+// A spike in unwrap failures usually means that someone is scraping or guessing IDs, or that a client is broken.
+//
+// Observer methods are called synchronously and possibly from many goroutines at once,
+// so they should be fast and safe for concurrent use.
+type Observer interface {
+	// Wrapped is called after an integer is wrapped.
+	Wrapped(d time.Duration)
+	// Unwrapped is called after a string is unwrapped successfully.
+	Unwrapped(d time.Duration)
+	// UnwrapFailed is called after Unwrap returns an error of the provided kind.
+	UnwrapFailed(kind ErrorKind, d time.Duration)
+}
+
+// latencyBuckets are the upper bounds of the buckets of a latencyHistogram.
+var latencyBuckets = [...]time.Duration{
+	time.Microsecond, 2 * time.Microsecond, 5 * time.Microsecond,
+	10 * time.Microsecond, 20 * time.Microsecond, 50 * time.Microsecond,
+	100 * time.Microsecond, 200 * time.Microsecond, 500 * time.Microsecond,
+	time.Millisecond, 10 * time.Millisecond, 100 * time.Millisecond,
+}
+
+// latencyHistogram is an expvar.Var counting durations in latencyBuckets.
+// The last count is for durations longer than every bucket.
+type latencyHistogram struct {
+	counts [len(latencyBuckets) + 1]atomic.Uint64
+	sum    atomic.Uint64
+}
+
+func (h *latencyHistogram) observe(d time.Duration) {
+	i := 0
+	for i < len(latencyBuckets) && d > latencyBuckets[i] {
+		i++
+	}
+	h.counts[i].Add(1)
+	h.sum.Add(uint64(d))
+}
+
+// String returns the histogram as a JSON object with the total count, the sum of the durations in nanoseconds
+// and the count of each bucket keyed by its upper bound, where "+Inf" has no upper bound.
+func (h *latencyHistogram) String() string {
+	var b strings.Builder
+	var count uint64
+	b.WriteString(`{"buckets": {`)
+	for i := range h.counts {
+		n := h.counts[i].Load()
+		count += n
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		bound := "+Inf"
+		if i < len(latencyBuckets) {
+			bound = latencyBuckets[i].String()
+		}
+		b.WriteString(strconv.Quote(bound) + ": " + strconv.FormatUint(n, 10))
+	}
+	b.WriteString(`}, "count": ` + strconv.FormatUint(count, 10))
+	b.WriteString(`, "sum_ns": ` + strconv.FormatUint(h.sum.Load(), 10) + "}")
+	return b.String()
+}
+
+// ExpvarObserver is an Observer which publishes counters and latency histograms using expvar.
+//
+// The published map contains:
+//
+//	wrap            number of integers wrapped
+//	unwrap          number of strings unwrapped successfully
+//	unwrap_errors   number of failed unwraps by error kind, such as invalid_char or rejected
+//	wrap_latency    histogram of Wrap durations
+//	unwrap_latency  histogram of Unwrap durations, including failures
+type ExpvarObserver struct {
+	wrap          expvar.Int
+	unwrap        expvar.Int
+	unwrapErrors  expvar.Map
+	wrapLatency   latencyHistogram
+	unwrapLatency latencyHistogram
+}
+
+// NewExpvarObserver returns an ExpvarObserver whose metrics are published as an expvar.Map under name.
+// Like expvar.Publish, it panics if name is already in use.
+func NewExpvarObserver(name string) *ExpvarObserver {
+	m := new(expvar.Map)
+	o := newExpvarObserver(m)
+	expvar.Publish(name, m)
+	return o
+}
+
+// newExpvarObserver returns an ExpvarObserver whose metrics are stored in m, without publishing it.
+func newExpvarObserver(m *expvar.Map) *ExpvarObserver {
+	o := &ExpvarObserver{}
+	m.Set("wrap", &o.wrap)
+	m.Set("unwrap", &o.unwrap)
+	m.Set("unwrap_errors", &o.unwrapErrors)
+	m.Set("wrap_latency", &o.wrapLatency)
+	m.Set("unwrap_latency", &o.unwrapLatency)
+	return o
+}
+
+func (o *ExpvarObserver) Wrapped(d time.Duration) {
+	o.wrap.Add(1)
+	o.wrapLatency.observe(d)
+}
+
+func (o *ExpvarObserver) Unwrapped(d time.Duration) {
+	o.unwrap.Add(1)
+	o.unwrapLatency.observe(d)
+}
+
+func (o *ExpvarObserver) UnwrapFailed(kind ErrorKind, d time.Duration) {
+	o.unwrapErrors.Add(kind.String(), 1)
+	o.unwrapLatency.observe(d)
+}
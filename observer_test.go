@@ -0,0 +1,125 @@
+package presents_test
+
+import (
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yi-jiayu/presents"
+)
+
+func TestErrorKindOf(t *testing.T) {
+	testCases := []struct {
+		err  error
+		kind presents.ErrorKind
+		name string
+	}{
+		{presents.ErrLength, presents.KindLength, "length"},
+		{&presents.InvalidCharError{Pos: 1, Char: '-'}, presents.KindInvalidChar, "invalid_char"},
+		{presents.ErrOverflow, presents.KindOverflow, "overflow"},
+		{&presents.RejectedError{Err: presents.ErrOutOfRange}, presents.KindRejected, "rejected"},
+		{errors.New("other"), presents.KindOther, "other"},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.kind, presents.ErrorKindOf(tc.err), "%v", tc.err)
+		assert.Equal(t, tc.name, tc.kind.String())
+	}
+	assert.Equal(t, "ErrorKind(100)", presents.ErrorKind(100).String())
+}
+
+type recordingObserver struct {
+	mu        sync.Mutex
+	wrapped   int
+	unwrapped int
+	failures  []presents.ErrorKind
+}
+
+func (o *recordingObserver) Wrapped(d time.Duration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.wrapped++
+}
+
+func (o *recordingObserver) Unwrapped(d time.Duration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.unwrapped++
+}
+
+func (o *recordingObserver) UnwrapFailed(kind presents.ErrorKind, d time.Duration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.failures = append(o.failures, kind)
+}
+
+func TestObserver(t *testing.T) {
+	o := new(recordingObserver)
+	p, err := presents.New(make([]byte, 10), &presents.Options{Observer: o, MaxID: 1000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := p.Wrap(1)
+	p.Unwrap(s)
+	p.Unwrap("")
+	p.Unwrap("abc-")
+	p.Unwrap(p.Wrap(1001))
+	dst := make([]string, 100)
+	p.WrapBatch(dst, make([]uint64, 100))
+	p.UnwrapAll(dst, 4)
+	assert.Equal(t, 102, o.wrapped)
+	assert.Equal(t, 101, o.unwrapped)
+	assert.Equal(t, []presents.ErrorKind{presents.KindLength, presents.KindInvalidChar, presents.KindRejected}, o.failures)
+}
+
+// expvarRuns numbers the names published by TestExpvarObserver.
+var expvarRuns atomic.Int64
+
+func TestExpvarObserver(t *testing.T) {
+	o, m := presents.NewUnpublishedExpvarObserver()
+	p, err := presents.New(make([]byte, 10), &presents.Options{Observer: o})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Unwrap(p.Wrap(1))
+	p.Unwrap("abc-")
+	p.Unwrap("abc-")
+
+	var metrics struct {
+		Wrap         int
+		Unwrap       int
+		UnwrapErrors map[string]int `json:"unwrap_errors"`
+		WrapLatency  struct {
+			Buckets map[string]int
+			Count   int
+			SumNs   int64 `json:"sum_ns"`
+		} `json:"wrap_latency"`
+		UnwrapLatency struct {
+			Count int
+		} `json:"unwrap_latency"`
+	}
+	if err := json.Unmarshal([]byte(m.String()), &metrics); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 1, metrics.Wrap)
+	assert.Equal(t, 1, metrics.Unwrap)
+	assert.Equal(t, map[string]int{"invalid_char": 2}, metrics.UnwrapErrors)
+	assert.Equal(t, 1, metrics.WrapLatency.Count)
+	assert.Len(t, metrics.WrapLatency.Buckets, 13)
+	assert.Contains(t, metrics.WrapLatency.Buckets, "+Inf")
+	assert.True(t, metrics.WrapLatency.SumNs > 0)
+	assert.Equal(t, 3, metrics.UnwrapLatency.Count)
+
+	// the name is unique to each run, so that the test can be repeated
+	name := fmt.Sprintf("presents_test_%d", expvarRuns.Add(1))
+	presents.NewExpvarObserver(name)
+	assert.NotNil(t, expvar.Get(name))
+	assert.Panics(t, func() {
+		presents.NewExpvarObserver(name)
+	})
+}
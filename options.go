@@ -0,0 +1,340 @@
+package presents
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Option customises a Presents when it is passed to New or one of the other constructors.
+// Options are created by the With functions, and *Options is an Option too,
+// so existing code which passes an *Options or nil keeps working.
+//
+// The With functions check their arguments, and the constructors check that they do not conflict,
+// so mistakes such as shuffling without a seed or giving the same option twice are reported as errors
+// instead of silently producing a different codec.
+type Option interface {
+	apply(c *config) error
+}
+
+// config collects the effect of a list of Options.
+type config struct {
+	Options
+	namespace string
+	selfTest  bool
+	// secret is derived from the key by the constructors which know it, or nil.
+	secret []byte
+	// shuffleKey is the secret for KeyedShuffle, or nil to use the one derived from the key.
+	shuffleKey []byte
+
+	// set records which options have been given, to reject repeats and conflicts.
+	set map[string]bool
+	// legacy is true if an *Options has been given.
+	legacy bool
+}
+
+// newConfig applies options in order.
+// A nil Option or nil *Options has no effect.
+func newConfig(options []Option) (*config, error) {
+	c := &config{set: make(map[string]bool)}
+	for _, o := range options {
+		if o == nil {
+			continue
+		}
+		if err := o.apply(c); err != nil {
+			return nil, err
+		}
+	}
+	if c.set["WithShuffle"] && c.set["WithKeyedShuffle"] {
+		return nil, errors.New("presents: WithShuffle and WithKeyedShuffle cannot be combined")
+	}
+	if c.set["WithEncoder"] {
+		names := []string{"WithAlphabet", "WithShuffle", "WithKeyedShuffle", "WithConstantTime",
+			"WithBlocklist", "WithPadding", "WithGrouping"}
+		if _, ok := c.Encoder.(Alphabet); ok {
+			// an Alphabet is used as if it had been passed to WithAlphabet
+			names = names[:1]
+		}
+		for _, name := range names {
+			if c.set[name] {
+				return nil, fmt.Errorf("presents: WithEncoder cannot be combined with %s", name)
+			}
+		}
+	}
+	return c, nil
+}
+
+// once records that the option name has been given, and returns an error if it already had been
+// or if it is being combined with an *Options.
+func (c *config) once(name string) error {
+	if c.legacy {
+		return fmt.Errorf("presents: %s cannot be combined with *Options", name)
+	}
+	if c.set[name] {
+		return fmt.Errorf("presents: %s given more than once", name)
+	}
+	c.set[name] = true
+	return nil
+}
+
+func (o *Options) apply(c *config) error {
+	if o == nil {
+		return nil
+	}
+	if c.legacy || len(c.set) > 0 {
+		return errors.New("presents: *Options cannot be combined with other options")
+	}
+	c.legacy = true
+	c.Options = *o
+	c.Blocklist = append([]string(nil), o.Blocklist...)
+	return nil
+}
+
+// optionFunc adapts a function to an Option.
+type optionFunc func(c *config) error
+
+func (f optionFunc) apply(c *config) error {
+	return f(c)
+}
+
+// WithAlphabet sets the alphabet used to encode strings, which must contain at least two distinct characters.
+func WithAlphabet(alphabet string) Option {
+	return optionFunc(func(c *config) error {
+		if err := c.once("WithAlphabet"); err != nil {
+			return err
+		}
+		if _, err := newAlphabet(alphabet); err != nil {
+			return err
+		}
+		c.Alphabet = alphabet
+		return nil
+	})
+}
+
+// WithShuffle permutes the alphabet using math/rand seeded with seed.
+// The permutation only depends on the seed, which is not secret; WithKeyedShuffle uses the key instead.
+func WithShuffle(seed int64) Option {
+	return optionFunc(func(c *config) error {
+		if err := c.once("WithShuffle"); err != nil {
+			return err
+		}
+		c.Shuffle = true
+		c.Seed = seed
+		return nil
+	})
+}
+
+// WithKeyedShuffle permutes the alphabet using a secret derived from the key.
+func WithKeyedShuffle() Option {
+	return optionFunc(func(c *config) error {
+		if err := c.once("WithKeyedShuffle"); err != nil {
+			return err
+		}
+		c.KeyedShuffle = true
+		return nil
+	})
+}
+
+// WithConstantTime is the equivalent of Options.ConstantTime.
+func WithConstantTime() Option {
+	return optionFunc(func(c *config) error {
+		if err := c.once("WithConstantTime"); err != nil {
+			return err
+		}
+		c.ConstantTime = true
+		return nil
+	})
+}
+
+// WithMaxID makes Unwrap reject IDs larger than max with a *RejectedError.
+func WithMaxID(max uint64) Option {
+	return optionFunc(func(c *config) error {
+		if err := c.once("WithMaxID"); err != nil {
+			return err
+		}
+		if max == 0 {
+			return errors.New("presents: WithMaxID: max must be positive")
+		}
+		c.MaxID = max
+		return nil
+	})
+}
+
+// WithValidator makes Unwrap reject IDs for which validate returns an error with a *RejectedError.
+func WithValidator(validate func(n uint64) error) Option {
+	return optionFunc(func(c *config) error {
+		if err := c.once("WithValidator"); err != nil {
+			return err
+		}
+		if validate == nil {
+			return errors.New("presents: WithValidator: validate is nil")
+		}
+		c.Validate = validate
+		return nil
+	})
+}
+
+// WithCanaries is the equivalent of Options.Canaries.
+func WithCanaries(canaries *Canaries) Option {
+	return optionFunc(func(c *config) error {
+		if err := c.once("WithCanaries"); err != nil {
+			return err
+		}
+		if canaries == nil {
+			return errors.New("presents: WithCanaries: canaries is nil")
+		}
+		c.Canaries = canaries
+		return nil
+	})
+}
+
+// WithObserver is the equivalent of Options.Observer.
+func WithObserver(observer Observer) Option {
+	return optionFunc(func(c *config) error {
+		if err := c.once("WithObserver"); err != nil {
+			return err
+		}
+		if observer == nil {
+			return errors.New("presents: WithObserver: observer is nil")
+		}
+		c.Observer = observer
+		return nil
+	})
+}
+
+// WithBlocklist makes Wrap avoid strings containing any of words, as described for Options.Blocklist.
+// The words are copied.
+func WithBlocklist(words ...string) Option {
+	words = append([]string(nil), words...)
+	return optionFunc(func(c *config) error {
+		if err := c.once("WithBlocklist"); err != nil {
+			return err
+		}
+		if len(newBlocklist(words)) == 0 {
+			return errors.New("presents: WithBlocklist: no words")
+		}
+		c.Blocklist = words
+		return nil
+	})
+}
+
+// WithPadding pads strings to the same length, as described for Options.Padding.
+func WithPadding() Option {
+	return optionFunc(func(c *config) error {
+		if err := c.once("WithPadding"); err != nil {
+			return err
+		}
+		c.Padding = true
+		return nil
+	})
+}
+
+// WithGrouping splits strings into groups of size characters separated by sep, as described for Options.GroupSize.
+func WithGrouping(size int, sep rune) Option {
+	return optionFunc(func(c *config) error {
+		if err := c.once("WithGrouping"); err != nil {
+			return err
+		}
+		if size <= 0 {
+			return fmt.Errorf("presents: WithGrouping: size must be positive, not %d", size)
+		}
+		c.GroupSize = size
+		c.Separator = sep
+		return nil
+	})
+}
+
+// WithEncoder converts encrypted blocks to and from text using e instead of the alphabet.
+// It cannot be combined with the options which customise the alphabet,
+// unless e is an Alphabet, in which case it is used as if it had been passed to WithAlphabet.
+func WithEncoder(e Encoder) Option {
+	return optionFunc(func(c *config) error {
+		if err := c.once("WithEncoder"); err != nil {
+			return err
+		}
+		if e == nil {
+			return errors.New("presents: WithEncoder: encoder is nil")
+		}
+		c.Encoder = e
+		return nil
+	})
+}
+
+// WithNamespace separates the IDs of different kinds of objects wrapped with the same key,
+// so that, for example, the string for user 42 does not also unwrap as order 42.
+// Codecs with different namespaces behave like codecs with unrelated keys.
+//
+// Each integer is encrypted twice, with a secret derived from the key and namespace mixed in between,
+// so namespaced codecs are about half as fast and do not use the bitsliced WrapBatch path.
+func WithNamespace(namespace string) Option {
+	return optionFunc(func(c *config) error {
+		if err := c.once("WithNamespace"); err != nil {
+			return err
+		}
+		if namespace == "" {
+			return errors.New("presents: WithNamespace: namespace is empty")
+		}
+		c.namespace = namespace
+		return nil
+	})
+}
+
+// WithSelfTest makes the constructor call SelfTest on the new Presents, and return its error if it fails.
+// It can be combined with an *Options.
+func WithSelfTest() Option {
+	return selfTestOption{}
+}
+
+type selfTestOption struct{}
+
+func (selfTestOption) apply(c *config) error {
+	if c.selfTest {
+		return errors.New("presents: WithSelfTest given more than once")
+	}
+	c.selfTest = true
+	return nil
+}
+
+// passphraseShuffle is used by NewFromPassphrase to shuffle the alphabet with a secret derived from the passphrase,
+// as KeyedShuffle does with a secret derived from the key.
+// Unlike WithKeyedShuffle, it may be combined with an *Options,
+// but it returns an error if the options already shuffle the alphabet.
+type passphraseShuffle []byte
+
+func (s passphraseShuffle) apply(c *config) error {
+	if c.Shuffle || c.KeyedShuffle {
+		return errors.New("presents: NewFromPassphrase: ShuffleAlphabet cannot be combined with another shuffle")
+	}
+	c.KeyedShuffle = true
+	c.shuffleKey = s
+	return nil
+}
+
+// namespacePermutation separates namespaces by encrypting twice with a secret mask mixed in between.
+type namespacePermutation struct {
+	inner Permutation
+	mask  uint64
+}
+
+// newNamespacePermutation derives the mask for namespace from secret, which is derived from the key as for KeyedShuffle.
+func newNamespacePermutation(inner Permutation, secret []byte, namespace string) namespacePermutation {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("presents namespace\x00"))
+	mac.Write([]byte(namespace))
+	m := binary.BigEndian.Uint64(mac.Sum(nil)) & mask(inner.Bits())
+	return namespacePermutation{inner: inner, mask: m}
+}
+
+func (n namespacePermutation) Bits() int {
+	return n.inner.Bits()
+}
+
+func (n namespacePermutation) Permute(x uint64) uint64 {
+	return n.inner.Permute(n.inner.Permute(x) ^ n.mask)
+}
+
+func (n namespacePermutation) Unpermute(x uint64) uint64 {
+	return n.inner.Unpermute(n.inner.Unpermute(x) ^ n.mask)
+}
@@ -0,0 +1,191 @@
+package presents_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yi-jiayu/presents"
+)
+
+func TestNew_options(t *testing.T) {
+	key := make([]byte, 10)
+	testCases := []struct {
+		name    string
+		options []presents.Option
+		legacy  *presents.Options
+	}{
+		{"none", nil, nil},
+		{"alphabet", []presents.Option{presents.WithAlphabet("0123456789abcdef")}, &presents.Options{Alphabet: "0123456789abcdef"}},
+		{"shuffle", []presents.Option{presents.WithShuffle(42)}, &presents.Options{Shuffle: true, Seed: 42}},
+		{"keyed shuffle", []presents.Option{presents.WithKeyedShuffle()}, &presents.Options{KeyedShuffle: true}},
+		{
+			"padding and grouping",
+			[]presents.Option{presents.WithPadding(), presents.WithGrouping(4, ' ')},
+			&presents.Options{Padding: true, GroupSize: 4, Separator: ' '},
+		},
+		{"encoder", []presents.Option{presents.WithEncoder(presents.Proquint{})}, &presents.Options{Encoder: presents.Proquint{}}},
+		{
+			"alphabet encoder",
+			[]presents.Option{presents.WithEncoder(presents.Alphabet("abcdefgh")), presents.WithShuffle(1)},
+			&presents.Options{Encoder: presents.Alphabet("abcdefgh"), Shuffle: true, Seed: 1},
+		},
+		{"blocklist", []presents.Option{presents.WithBlocklist("a", "b")}, &presents.Options{Blocklist: []string{"a", "b"}}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := presents.New(key, tc.options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			legacy, err := presents.New(key, tc.legacy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, n := range []uint64{0, 1, 1213486160, 1<<64 - 1} {
+				s := p.Wrap(n)
+				assert.Equal(t, legacy.Wrap(n), s)
+				m, err := p.Unwrap(s)
+				assert.NoError(t, err)
+				assert.Equal(t, n, m)
+			}
+		})
+	}
+}
+
+func TestNew_optionErrors(t *testing.T) {
+	key := make([]byte, 10)
+	testCases := []struct {
+		name    string
+		options []presents.Option
+		err     string
+	}{
+		{"repeated", []presents.Option{presents.WithPadding(), presents.WithPadding()}, "presents: WithPadding given more than once"},
+		{
+			"shuffle conflict",
+			[]presents.Option{presents.WithShuffle(1), presents.WithKeyedShuffle()},
+			"presents: WithShuffle and WithKeyedShuffle cannot be combined",
+		},
+		{
+			"encoder conflict",
+			[]presents.Option{presents.WithPadding(), presents.WithEncoder(presents.Proquint{})},
+			"presents: WithEncoder cannot be combined with WithPadding",
+		},
+		{
+			"alphabet encoder conflict",
+			[]presents.Option{presents.WithAlphabet("abc"), presents.WithEncoder(presents.Alphabet("abc"))},
+			"presents: WithEncoder cannot be combined with WithAlphabet",
+		},
+		{
+			"legacy after option",
+			[]presents.Option{presents.WithPadding(), &presents.Options{}},
+			"presents: *Options cannot be combined with other options",
+		},
+		{
+			"option after legacy",
+			[]presents.Option{&presents.Options{}, presents.WithPadding()},
+			"presents: WithPadding cannot be combined with *Options",
+		},
+		{"bad alphabet", []presents.Option{presents.WithAlphabet("a")}, "presents: alphabet must contain at least two characters"},
+		{"duplicate character", []presents.Option{presents.WithAlphabet("abca")}, "presents: all characters in alphabet must be unique"},
+		{
+			"non-ASCII alphabet",
+			[]presents.Option{presents.WithAlphabet("0123456789é")},
+			`presents: alphabet must only contain ASCII characters, not 'é'`,
+		},
+		{
+			"legacy non-ASCII alphabet",
+			[]presents.Option{&presents.Options{Alphabet: "0123456789é"}},
+			`presents: alphabet must only contain ASCII characters, not 'é'`,
+		},
+		{"zero max", []presents.Option{presents.WithMaxID(0)}, "presents: WithMaxID: max must be positive"},
+		{"nil validator", []presents.Option{presents.WithValidator(nil)}, "presents: WithValidator: validate is nil"},
+		{"nil canaries", []presents.Option{presents.WithCanaries(nil)}, "presents: WithCanaries: canaries is nil"},
+		{"nil observer", []presents.Option{presents.WithObserver(nil)}, "presents: WithObserver: observer is nil"},
+		{"nil encoder", []presents.Option{presents.WithEncoder(nil)}, "presents: WithEncoder: encoder is nil"},
+		{"empty blocklist", []presents.Option{presents.WithBlocklist("")}, "presents: WithBlocklist: no words"},
+		{"group size", []presents.Option{presents.WithGrouping(0, '-')}, "presents: WithGrouping: size must be positive, not 0"},
+		{
+			"separator in alphabet",
+			[]presents.Option{presents.WithGrouping(4, 'A')},
+			`presents: separator 'A' is in the alphabet`,
+		},
+		{"empty namespace", []presents.Option{presents.WithNamespace("")}, "presents: WithNamespace: namespace is empty"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := presents.New(key, tc.options...)
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func TestNew_optionsCopied(t *testing.T) {
+	key := make([]byte, 10)
+	words := []string{"foo"}
+	o := &presents.Options{Alphabet: "abcdefgh", Blocklist: words}
+	p, err := presents.New(key, o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, err := presents.New(key, presents.WithAlphabet("abcdefgh"), presents.WithBlocklist(words...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := p.Wrap(12345)
+	o.Alphabet = "01234567"
+	words[0] = "bar"
+	assert.Equal(t, want, p.Wrap(12345))
+	assert.Equal(t, want, q.Wrap(12345))
+}
+
+func TestWithNamespace(t *testing.T) {
+	key := make([]byte, 10)
+	users, err := presents.New(key, presents.WithNamespace("users"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	orders, err := presents.New(key, presents.WithNamespace("orders"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, err := presents.New(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range []uint64{0, 1, 42, 1213486160, 1<<64 - 1} {
+		s := users.Wrap(n)
+		assert.NotEqual(t, s, orders.Wrap(n))
+		assert.NotEqual(t, s, plain.Wrap(n))
+		m, err := users.Unwrap(s)
+		assert.NoError(t, err)
+		assert.Equal(t, n, m)
+		m, err = orders.Unwrap(s)
+		assert.NoError(t, err)
+		assert.NotEqual(t, n, m)
+	}
+
+	ns := []uint64{0, 1, 2, 3, 1 << 40}
+	batch := make([]string, len(ns))
+	users.WrapBatch(batch, ns)
+	for i, n := range ns {
+		assert.Equal(t, users.Wrap(n), batch[i])
+	}
+}
+
+func TestNewFromPassphrase_options(t *testing.T) {
+	kdf := &presents.PassphraseOptions{Iterations: 1, ShuffleAlphabet: true}
+	p, err := presents.NewFromPassphrase("passphrase", "salt", kdf, presents.WithAlphabet("0123456789"), presents.WithPadding())
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, err := presents.NewFromPassphrase("passphrase", "salt", kdf, &presents.Options{Alphabet: "0123456789", Padding: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, q.Wrap(1), p.Wrap(1))
+	assert.Len(t, p.Wrap(1), 20)
+
+	_, err = presents.NewFromPassphrase("passphrase", "salt", kdf, presents.WithAlphabet("0123456789"), presents.WithAlphabet("01"))
+	assert.True(t, err != nil && !errors.Is(err, presents.ErrUnknownCipher))
+}
@@ -0,0 +1,323 @@
+package presents
+
+import (
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc64"
+	"strings"
+	"unicode/utf8"
+)
+
+// Permutation is the first stage of a Pipeline.
+// It is a keyed bijection on the integers below 2^Bits(), such as a block cipher or format-preserving encryption.
+type Permutation interface {
+	// Bits returns the size of the domain in bits, between 1 and 64.
+	Bits() int
+	// Permute maps an integer in the domain to another.
+	Permute(n uint64) uint64
+	// Unpermute is the inverse of Permute.
+	Unpermute(n uint64) uint64
+}
+
+// cipherPermutation is a Permutation on 64-bit integers using a cipher.Block with a 64-bit block size.
+type cipherPermutation struct {
+	block cipher.Block
+}
+
+// NewCipherPermutation returns a Permutation which encrypts integers as big-endian blocks using c.
+// c must have a 64-bit block size.
+func NewCipherPermutation(c cipher.Block) (Permutation, error) {
+	if c.BlockSize() != 8 {
+		return nil, errors.New("presents: NewCipherPermutation: cipher should have a 64-bit block size")
+	}
+	return cipherPermutation{block: c}, nil
+}
+
+func (c cipherPermutation) Bits() int {
+	return 64
+}
+
+func (c cipherPermutation) Permute(n uint64) uint64 {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, n)
+	dst := make([]byte, 8)
+	c.block.Encrypt(dst, b)
+	return binary.BigEndian.Uint64(dst)
+}
+
+func (c cipherPermutation) Unpermute(n uint64) uint64 {
+	src := make([]byte, 8)
+	binary.BigEndian.PutUint64(src, n)
+	dst := make([]byte, 8)
+	c.block.Decrypt(dst, src)
+	return binary.BigEndian.Uint64(dst)
+}
+
+// feistelRounds is the number of rounds used by NewFeistel. It must be even.
+const feistelRounds = 10
+
+// feistel is a format-preserving Permutation built from an unbalanced Feistel network.
+type feistel struct {
+	key  []byte
+	bits int
+}
+
+// NewFeistel returns a format-preserving Permutation on integers of between 2 and 64 bits,
+// for when IDs should be shorter than a 64-bit block cipher allows, or to leave room for an Integrity tag.
+// It is a Feistel network whose round function is HMAC-SHA256 keyed with key.
+func NewFeistel(key []byte, bits int) (Permutation, error) {
+	if len(key) == 0 {
+		return nil, errors.New("presents: NewFeistel: key is empty")
+	}
+	if bits < 2 || bits > 64 {
+		return nil, fmt.Errorf("presents: NewFeistel: bits must be between 2 and 64, not %d", bits)
+	}
+	return &feistel{key: append([]byte(nil), key...), bits: bits}, nil
+}
+
+func (f *feistel) Bits() int {
+	return f.bits
+}
+
+func (f *feistel) Permute(n uint64) uint64 {
+	// the halves have widths wl and wr, which swap every round
+	wl, wr := f.bits/2, f.bits-f.bits/2
+	l, r := n>>uint(wr), n&mask(wr)
+	for i := 0; i < feistelRounds; i++ {
+		l, r = r, l^f.round(i, r, wl)
+		wl, wr = wr, wl
+	}
+	return l<<uint(wr) | r
+}
+
+func (f *feistel) Unpermute(n uint64) uint64 {
+	wl, wr := f.bits/2, f.bits-f.bits/2
+	l, r := n>>uint(wr), n&mask(wr)
+	for i := feistelRounds - 1; i >= 0; i-- {
+		l, r = r^f.round(i, l, wr), l
+		wl, wr = wr, wl
+	}
+	return l<<uint(wr) | r
+}
+
+// round returns the round function for round i applied to x, truncated to width bits.
+func (f *feistel) round(i int, x uint64, width int) uint64 {
+	mac := hmac.New(sha256.New, f.key)
+	var b [10]byte
+	b[0] = byte(i)
+	b[1] = byte(f.bits)
+	binary.BigEndian.PutUint64(b[2:], x)
+	mac.Write(b[:])
+	return binary.BigEndian.Uint64(mac.Sum(nil)) & mask(width)
+}
+
+// mask returns a mask of the lowest bits bits.
+func mask(bits int) uint64 {
+	if bits >= 64 {
+		return ^uint64(0)
+	}
+	return 1<<uint(bits) - 1
+}
+
+// Integrity is the optional second stage of a Pipeline.
+// It computes a tag which is stored next to the output of the Permutation,
+// so that Unwrap can reject strings which were not produced by Wrap with ErrChecksum.
+type Integrity interface {
+	// Bits returns the size of the tag in bits.
+	Bits() int
+	// Tag computes the tag for x, the output of the Permutation.
+	Tag(x uint64) uint64
+}
+
+type checksum struct {
+	bits int
+}
+
+var crc64Table = crc64.MakeTable(crc64.ECMA)
+
+// NewChecksum returns an Integrity stage which uses the lowest bits bits of the CRC-64 of the permuted value as its tag.
+// It detects typos and random strings, but anyone can compute it, so it does not stop forgeries.
+func NewChecksum(bits int) (Integrity, error) {
+	if bits < 1 || bits > 63 {
+		return nil, fmt.Errorf("presents: NewChecksum: bits must be between 1 and 63, not %d", bits)
+	}
+	return checksum{bits: bits}, nil
+}
+
+func (c checksum) Bits() int {
+	return c.bits
+}
+
+func (c checksum) Tag(x uint64) uint64 {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], x)
+	return crc64.Checksum(b[:], crc64Table) & mask(c.bits)
+}
+
+type macIntegrity struct {
+	key  []byte
+	bits int
+}
+
+// NewMAC returns an Integrity stage which uses the first bits bits of HMAC-SHA256 of the permuted value as its tag.
+// Without the key, a valid string can only be guessed with probability 2^-bits.
+func NewMAC(key []byte, bits int) (Integrity, error) {
+	if len(key) == 0 {
+		return nil, errors.New("presents: NewMAC: key is empty")
+	}
+	if bits < 1 || bits > 63 {
+		return nil, fmt.Errorf("presents: NewMAC: bits must be between 1 and 63, not %d", bits)
+	}
+	return macIntegrity{key: append([]byte(nil), key...), bits: bits}, nil
+}
+
+func (m macIntegrity) Bits() int {
+	return m.bits
+}
+
+func (m macIntegrity) Tag(x uint64) uint64 {
+	mac := hmac.New(sha256.New, m.key)
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], x)
+	mac.Write(b[:])
+	return binary.BigEndian.Uint64(mac.Sum(nil)) >> uint(64-m.bits)
+}
+
+// Decorator is the last stage of a Pipeline, which changes the appearance of the encoded string.
+// Decorators are applied in order by Wrap, and removed in reverse order by Unwrap.
+type Decorator interface {
+	Decorate(s string) string
+	// Undecorate is the inverse of Decorate.
+	// It should return errors matching the sentinel errors, such as ErrInvalidChar, where they apply.
+	Undecorate(s string) (string, error)
+}
+
+// positionMapper is implemented by decorators which can report where a character of their undecorated output
+// came from, so that the positions in *InvalidCharErrors refer to the string passed to Unwrap.
+type positionMapper interface {
+	// decoratedPos converts a byte offset into the output of Undecorate(s) to a byte offset into s.
+	decoratedPos(s string, pos int) int
+}
+
+// Prefix is a Decorator which adds a fixed prefix to strings, such as "usr_".
+// Undecorate returns an error if the prefix is missing.
+type Prefix string
+
+func (p Prefix) Decorate(s string) string {
+	return string(p) + s
+}
+
+func (p Prefix) Undecorate(s string) (string, error) {
+	if strings.HasPrefix(s, string(p)) {
+		return s[len(p):], nil
+	}
+	for i := 0; i < len(s) && i < len(p); i++ {
+		if s[i] != p[i] {
+			c, _ := utf8.DecodeRuneInString(s[i:])
+			return "", &InvalidCharError{Pos: i, Char: c}
+		}
+	}
+	return "", ErrLength
+}
+
+func (p Prefix) decoratedPos(s string, pos int) int {
+	return pos + len(p)
+}
+
+// Pipeline describes the stages used to convert integers to strings, and is assembled into a Presents by Build.
+//
+// Wrap passes an integer through each stage in turn:
+//
+//	Permutation  scrambles the integer, for example by encrypting it with a block cipher
+//	Integrity    optionally appends a tag to the permuted value, making room by shifting it left
+//	Encoder      converts the result to text, using DefaultAlphabet if it is nil
+//	Decorators   change the appearance of the text, for example by adding a prefix or grouping it
+//
+// and Unwrap reverses them. New, NewTripleDES and NewWithCipher are presets which use a cipher permutation,
+// no integrity check and an Alphabet.
+type Pipeline struct {
+	Permutation Permutation
+	Integrity   Integrity
+	Encoder     Encoder
+	Decorators  []Decorator
+}
+
+// Build checks that the stages of the pipeline fit together, and returns a Presents which uses them.
+// It returns an error if the permutation and tag need more than 64 bits,
+// or more bits than a BoundedEncoder can represent, or if a decorator cannot be used with the encoder.
+func (pl Pipeline) Build() (*Presents, error) {
+	if pl.Permutation == nil {
+		return nil, errors.New("presents: Pipeline: no Permutation")
+	}
+	bits := pl.Permutation.Bits()
+	if bits < 1 || bits > 64 {
+		return nil, fmt.Errorf("presents: Pipeline: Permutation has %d bits", bits)
+	}
+	if pl.Integrity != nil {
+		tagBits := pl.Integrity.Bits()
+		if tagBits < 1 || bits+tagBits > 64 {
+			return nil, fmt.Errorf("presents: Pipeline: %d-bit Permutation and %d-bit Integrity tag do not fit in 64 bits",
+				bits, tagBits)
+		}
+		bits += tagBits
+	}
+
+	p := &Presents{
+		permutation: pl.Permutation,
+		integrity:   pl.Integrity,
+		alphabet:    DefaultAlphabet,
+	}
+	switch e := pl.Encoder.(type) {
+	case nil:
+	case Alphabet:
+		a, err := newAlphabet(string(e))
+		if err != nil {
+			return nil, err
+		}
+		p.alphabet = a
+	default:
+		p.encoder = e
+	}
+	if e, ok := pl.Encoder.(BoundedEncoder); ok && e.Bits() < bits {
+		return nil, fmt.Errorf("presents: Pipeline: Encoder can only represent %d bits, but %d are needed", e.Bits(), bits)
+	}
+
+	// chars holds the characters which may appear in the input to the next decorator, if they are known
+	chars, charsKnown := p.charset()
+	for _, d := range pl.Decorators {
+		switch d := d.(type) {
+		case nil:
+			return nil, errors.New("presents: Pipeline: nil Decorator")
+		case padding:
+			if p.encoder != nil {
+				return nil, errors.New("presents: Pipeline: Padding can only be used with an Alphabet encoder")
+			}
+			d.width = p.alphabet.encodedLen(^uint64(0))
+			d.char = p.alphabet[0]
+			p.decorators = append(p.decorators, d)
+			continue
+		case Grouping:
+			if d.Size <= 0 {
+				return nil, errors.New("presents: Pipeline: Grouping size must be positive")
+			}
+			if p.encoder == nil && strings.ContainsRune(string(p.alphabet), d.separator()) {
+				return nil, fmt.Errorf("presents: separator %q is in the alphabet", d.separator())
+			}
+			if !charsKnown {
+				return nil, errors.New("presents: Pipeline: Grouping can only be used with an Encoder whose characters are known")
+			}
+			if i := strings.IndexFunc(chars, d.ignored); i >= 0 {
+				r, _ := utf8.DecodeRuneInString(chars[i:])
+				return nil, fmt.Errorf("presents: Pipeline: Grouping would remove %q, which appears in the encoded string", r)
+			}
+		case Prefix:
+			chars += string(d)
+		}
+		p.decorators = append(p.decorators, d)
+	}
+	return p, nil
+}
@@ -0,0 +1,291 @@
+package presents_test
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yi-jiayu/PRESENT.go"
+	"github.com/yi-jiayu/presents"
+)
+
+func newPresentPermutation(t *testing.T) presents.Permutation {
+	c, err := present.NewCipher(make([]byte, 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	perm, err := presents.NewCipherPermutation(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return perm
+}
+
+func TestPipeline_presets(t *testing.T) {
+	perm := newPresentPermutation(t)
+	testCases := []struct {
+		pipeline presents.Pipeline
+		options  *presents.Options
+	}{
+		{presents.Pipeline{Permutation: perm}, nil},
+		{
+			presents.Pipeline{Permutation: perm, Decorators: []presents.Decorator{presents.Padding(), presents.Grouping{Size: 4}}},
+			&presents.Options{Padding: true, GroupSize: 4},
+		},
+		{
+			presents.Pipeline{Permutation: perm, Encoder: presents.Alphabet("0123456789abcdef")},
+			&presents.Options{Alphabet: "0123456789abcdef"},
+		},
+		{presents.Pipeline{Permutation: perm, Encoder: presents.Proquint{}}, &presents.Options{Encoder: presents.Proquint{}}},
+	}
+	for _, tc := range testCases {
+		p, err := tc.pipeline.Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, err := presents.New(make([]byte, 10), tc.options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, n := range []uint64{0, 1, 1213486160, 1<<64 - 1} {
+			s := p.Wrap(n)
+			assert.Equal(t, expected.Wrap(n), s)
+			actual, err := p.Unwrap(s)
+			assert.NoError(t, err)
+			assert.Equal(t, n, actual)
+		}
+	}
+}
+
+func TestNewFeistel(t *testing.T) {
+	for _, bits := range []int{2, 8, 9} {
+		perm, err := presents.NewFeistel([]byte("key"), bits)
+		if err != nil {
+			t.Fatal(err)
+		}
+		seen := make(map[uint64]bool)
+		for n := uint64(0); n < 1<<uint(bits); n++ {
+			x := perm.Permute(n)
+			assert.True(t, x < 1<<uint(bits), "%d-bit permutation of %d is %d", bits, n, x)
+			assert.False(t, seen[x])
+			seen[x] = true
+			assert.Equal(t, n, perm.Unpermute(x))
+		}
+	}
+	perm, err := presents.NewFeistel([]byte("key"), 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range []uint64{0, 1, 1213486160, 1<<64 - 1} {
+		assert.Equal(t, n, perm.Unpermute(perm.Permute(n)))
+	}
+	_, err = presents.NewFeistel([]byte("key"), 1)
+	assert.EqualError(t, err, "presents: NewFeistel: bits must be between 2 and 64, not 1")
+	_, err = presents.NewFeistel(nil, 32)
+	assert.Error(t, err)
+}
+
+func TestPipeline_integrity(t *testing.T) {
+	perm, err := presents.NewFeistel([]byte("key"), 40)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mac, err := presents.NewMAC([]byte("mac key"), 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checksum, err := presents.NewChecksum(16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, integrity := range []presents.Integrity{mac, checksum} {
+		p, err := presents.Pipeline{Permutation: perm, Integrity: integrity}.Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := p.Wrap(1213486160)
+		n, err := p.Unwrap(s)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(1213486160), n)
+
+		// changing any character should almost always be detected
+		rejected := 0
+		for i := range s {
+			b := []byte(s)
+			b[i] = "0123456789"[(i+int(b[i]))%10]
+			if b[i] == s[i] {
+				b[i] = 'z'
+			}
+			_, err := p.Unwrap(string(b))
+			if errors.Is(err, presents.ErrChecksum) || errors.Is(err, presents.ErrOverflow) {
+				rejected++
+			}
+		}
+		assert.Equal(t, len(s), rejected)
+	}
+}
+
+func TestPipeline_Build_errors(t *testing.T) {
+	perm := newPresentPermutation(t)
+	feistel36, err := presents.NewFeistel([]byte("key"), 36)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mac, err := presents.NewMAC([]byte("mac key"), 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	digits, err := presents.NewFixedWidth("0123456789", 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 39, digits.Bits())
+	spaced, err := presents.NewWordList([]string{"zero", "one"}, " ")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		pipeline presents.Pipeline
+		err      string
+	}{
+		{presents.Pipeline{}, "presents: Pipeline: no Permutation"},
+		{presents.Pipeline{Permutation: perm, Integrity: mac},
+			"presents: Pipeline: 64-bit Permutation and 8-bit Integrity tag do not fit in 64 bits"},
+		{presents.Pipeline{Permutation: feistel36, Integrity: mac, Encoder: digits},
+			"presents: Pipeline: Encoder can only represent 39 bits, but 44 are needed"},
+		{presents.Pipeline{Permutation: perm, Encoder: presents.Proquint{}, Decorators: []presents.Decorator{presents.Padding()}},
+			"presents: Pipeline: Padding can only be used with an Alphabet encoder"},
+		{presents.Pipeline{Permutation: perm, Decorators: []presents.Decorator{presents.Grouping{Size: 4, Separator: 'x'}}},
+			"presents: separator 'x' is in the alphabet"},
+		{presents.Pipeline{Permutation: perm, Decorators: []presents.Decorator{presents.Grouping{}}},
+			"presents: Pipeline: Grouping size must be positive"},
+		{presents.Pipeline{Permutation: perm, Decorators: []presents.Decorator{nil}},
+			"presents: Pipeline: nil Decorator"},
+		{presents.Pipeline{Permutation: perm, Encoder: presents.Proquint{}, Decorators: []presents.Decorator{presents.Grouping{Size: 4}}},
+			"presents: Pipeline: Grouping would remove '-', which appears in the encoded string"},
+		{presents.Pipeline{Permutation: perm, Encoder: spaced, Decorators: []presents.Decorator{presents.Grouping{Size: 4, Separator: '.'}}},
+			"presents: Pipeline: Grouping would remove ' ', which appears in the encoded string"},
+		{presents.Pipeline{Permutation: perm, Decorators: []presents.Decorator{presents.Prefix("a.b"), presents.Grouping{Size: 4, Separator: '.'}}},
+			"presents: Pipeline: Grouping would remove '.', which appears in the encoded string"},
+		{presents.Pipeline{Permutation: perm, Encoder: hexEncoder{}, Decorators: []presents.Decorator{presents.Grouping{Size: 4}}},
+			"presents: Pipeline: Grouping can only be used with an Encoder whose characters are known"},
+	}
+	for _, tc := range testCases {
+		_, err := tc.pipeline.Build()
+		assert.EqualError(t, err, tc.err)
+	}
+}
+
+// hexEncoder is an Encoder whose characters Pipeline.Build does not know.
+type hexEncoder struct{}
+
+func (hexEncoder) Encode(n uint64) string {
+	return strconv.FormatUint(n, 16)
+}
+
+func (hexEncoder) Decode(s string) (uint64, error) {
+	return strconv.ParseUint(s, 16, 64)
+}
+
+func TestPipeline_decorators(t *testing.T) {
+	perm, err := presents.NewFeistel([]byte("key"), 36)
+	if err != nil {
+		t.Fatal(err)
+	}
+	digits, err := presents.NewFixedWidth("0123456789", 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoders := map[string]presents.Encoder{
+		"alphabet":    nil,
+		"proquint":    presents.Proquint{},
+		"word list":   presents.DefaultWordList(),
+		"fixed width": digits,
+	}
+	decorators := map[string][]presents.Decorator{
+		"padding":  {presents.Padding()},
+		"grouping": {presents.Grouping{Size: 4, Separator: '.'}},
+		"prefix":   {presents.Prefix("id_")},
+		"all":      {presents.Padding(), presents.Grouping{Size: 4, Separator: '.'}, presents.Prefix("id_")},
+	}
+	for encoderName, encoder := range encoders {
+		for decoratorName, decorators := range decorators {
+			name := encoderName + " with " + decoratorName
+			p, err := presents.Pipeline{Permutation: perm, Encoder: encoder, Decorators: decorators}.Build()
+			if encoder != nil && decorators[0] == presents.Padding() {
+				assert.EqualError(t, err, "presents: Pipeline: Padding can only be used with an Alphabet encoder", name)
+				continue
+			}
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			for _, n := range []uint64{0, 1, 1213486160, 1<<36 - 1} {
+				actual, err := p.Unwrap(p.Wrap(n))
+				assert.NoError(t, err, name)
+				assert.Equal(t, n, actual, name)
+			}
+		}
+	}
+}
+
+func TestPipeline_fixedWidth(t *testing.T) {
+	perm, err := presents.NewFeistel([]byte("key"), 36)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checksum, err := presents.NewChecksum(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	digits, err := presents.NewFixedWidth("0123456789", 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := presents.Pipeline{
+		Permutation: perm,
+		Integrity:   checksum,
+		Encoder:     digits,
+		Decorators:  []presents.Decorator{presents.Grouping{Size: 4, Separator: ' '}},
+	}.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range []uint64{0, 1, 1213486160, 1<<36 - 1} {
+		s := p.Wrap(n)
+		assert.Len(t, s, 14)
+		actual, err := p.Unwrap(s)
+		assert.NoError(t, err)
+		assert.Equal(t, n, actual)
+	}
+	_, err = p.TryWrap(1 << 36)
+	assert.Equal(t, presents.ErrDomain, err)
+	assert.Panics(t, func() {
+		p.Wrap(1 << 36)
+	})
+	_, err = p.Unwrap("1234 5678 9012 3")
+	assert.Equal(t, presents.ErrLength, err)
+}
+
+func TestPrefix(t *testing.T) {
+	p, err := presents.Pipeline{
+		Permutation: newPresentPermutation(t),
+		Decorators:  []presents.Decorator{presents.Grouping{Size: 4}, presents.Prefix("usr_")},
+	}.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := p.Wrap(1213486160)
+	assert.Equal(t, "usr_90Ny-XHLc-khA", s)
+	n, err := p.Unwrap(s)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1213486160), n)
+
+	_, err = p.Unwrap("org_90Ny-XHLc-khA")
+	assert.Equal(t, &presents.InvalidCharError{Pos: 0, Char: 'o'}, err)
+	_, err = p.Unwrap("usr")
+	assert.Equal(t, presents.ErrLength, err)
+	_, err = p.Unwrap("usr_90Ny-X_Lc-khA")
+	assert.Equal(t, &presents.InvalidCharError{Pos: 10, Char: '_'}, err)
+}
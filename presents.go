@@ -4,101 +4,521 @@
 package presents
 
 import (
+	"context"
 	"crypto/cipher"
 	"crypto/des"
+	"crypto/hmac"
+	"crypto/sha256"
 	"encoding/binary"
 	"errors"
-	"fmt"
+	"time"
 
 	"github.com/yi-jiayu/PRESENT.go"
 )
 
 // DefaultAlphabet contains printable characters from 0-9, A-Z and a-z, similar to a base62 encoding.
-const DefaultAlphabet = alphabet("0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz")
+const DefaultAlphabet = Alphabet("0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz")
 
 // Presents contains a cipher.Block implementing PRESENT
 // and an alphabet for converting between 64-bit integers and strings.
+// More generally, it runs the stages of a Pipeline.
+//
+// A Presents is never modified after it is created,
+// so it is safe for concurrent use by multiple goroutines
+// as long as its cipher.Block is.
+// The ciphers used by New and NewTripleDES are safe for concurrent use.
 type Presents struct {
-	cipher   cipher.Block
-	alphabet alphabet
+	permutation Permutation
+	integrity   Integrity
+	alphabet    Alphabet
+	// encoder replaces alphabet if it is not nil.
+	encoder    Encoder
+	decorators []Decorator
+
+	// batch is a bitsliced copy of cipher used by WrapBatch.
+	// It is nil unless the Presents was created by New.
+	batch *bitslicedPresent
+
+	// constantTime selects alphabet.decodeConstantTime over alphabet.Decode.
+	constantTime bool
+
+	maxID    uint64
+	validate func(n uint64) error
+	canaries *Canaries
+	observer Observer
+
+	// blocklist holds lowercase words which wrapped strings must not contain, or nil.
+	blocklist []string
 }
 
 // Options can be passed to New to customise the alphabet to be used.
+// The With functions, such as WithAlphabet, are an alternative which validates each option;
+// an *Options cannot be combined with them.
+//
+// Shuffle with Seed permutes the alphabet using math/rand, so the permutation only depends on a public seed.
+// If KeyedShuffle is true, the alphabet is instead permuted using a secret derived from the cipher key,
+// and Shuffle and Seed are ignored. The secret is derived from the key with HMAC-SHA256,
+// independently of the encryption of IDs, so it cannot be learnt from wrapped strings.
+// NewWithCipher does not know the key, so it derives the secret by decrypting fixed blocks with the cipher instead;
+// wrapped strings do not reveal it, but someone who can unwrap chosen strings and see the resulting integers could.
+//
+// If ConstantTime is true, Unwrap decodes strings in time which depends only on their length,
+// and New uses a PRESENT implementation without secret-dependent table lookups.
+// This is slower, and only protects the cipher if it is built in;
+// SPECK and SIMON are constant time by construction, but other ciphers passed to NewWithCipher may not be.
+//
+// Almost every string unwraps to some integer, and random strings usually unwrap to huge ones.
+// If MaxID is not zero, Unwrap rejects IDs larger than it, and if Validate is not nil,
+// Unwrap rejects IDs for which it returns an error.
+// Either way Unwrap returns a *RejectedError, so guessed strings can be filtered out before any database lookup.
+//
+// If Canaries is not nil, Unwrap calls its handler whenever a string unwraps to one of its IDs.
+//
+// If Observer is not nil, it is told the outcome and duration of every call to Wrap and Unwrap.
+//
+// If Blocklist is not empty, Wrap avoids strings containing any of its words, ignoring case.
+// When the usual string for an ID is blocked, it is encrypted again until the result is clean,
+// and the number of extra encryptions is recorded as an extra trailing digit, making the string one digit longer
+// than the longest usual string. Strings which are not blocked are the same as without a blocklist.
+// DefaultBlocklist is a list of English words to start from.
+//
+// If Padding is true, Wrap pads strings with the first character of the alphabet
+// to the length of the longest string it can produce, so that all strings have the same length,
+// except for strings which avoid the Blocklist, which are one character longer.
+// If GroupSize is positive, Wrap splits strings into groups of that many characters separated by Separator,
+// or DefaultSeparator if Separator is zero, as in "7HQ2-K9XM-3PD", to make them easier to read aloud.
+// Unwrap then ignores separators and whitespace anywhere in the string.
+//
+// If Encoder is not nil, it converts encrypted blocks to and from text instead of the alphabet,
+// for example to produce proquints or words. It cannot be combined with the options which customise the alphabet:
+// Alphabet, Shuffle, KeyedShuffle, ConstantTime, Blocklist, Padding and GroupSize,
+// unless it is an Alphabet, in which case it is used exactly as if it had been passed as Alphabet.
 type Options struct {
-	Alphabet string
-	Shuffle  bool
-	Seed     int64
+	Alphabet     string
+	Shuffle      bool
+	Seed         int64
+	KeyedShuffle bool
+	ConstantTime bool
+	MaxID        uint64
+	Validate     func(n uint64) error
+	Canaries     *Canaries
+	Observer     Observer
+	Blocklist    []string
+	Padding      bool
+	GroupSize    int
+	Separator    rune
+	Encoder      Encoder
 }
 
 // New creates a new Presents struct using the PRESENT block cipher.
-// If options.Alphabet is not the empty string, it will be used as the alphabet.
-// If options.Shuffle is true, the alphabet will be shuffled based on options.Seed.
-func New(key []byte, options *Options) (*Presents, error) {
+// The options customise the alphabet and other behaviour; see Option.
+// Passing nil or a single *Options is also supported:
+// if options.Alphabet is not the empty string, it will be used as the alphabet,
+// and if options.Shuffle is true, the alphabet will be shuffled based on options.Seed.
+func New(key []byte, options ...Option) (*Presents, error) {
 	c, err := present.NewCipher(key)
 	if err != nil {
-		return nil, fmt.Errorf("presents: New: %v", err)
+		return nil, &KeySizeError{Cipher: "present", Size: len(key), Valid: []int{10, 16}, Err: err}
 	}
-	return NewWithCipher(c, options)
+	cfg, err := newConfig(options)
+	if err != nil {
+		return nil, err
+	}
+	cfg.secret = deriveSecret(key)
+	if cfg.ConstantTime {
+		c = newConstantTimePresent(key)
+	}
+	p, err := newWithCipher(c, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.namespace == "" {
+		p.batch = newBitslicedPresent(key)
+	}
+	if cfg.selfTest {
+		if err := p.SelfTest(); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
 }
 
 // NewWithCipher returns a new Presents instance from the provided cipher.Block and options.
 // The provided cipher.Block should have a 64-bit block size.
-func NewWithCipher(c cipher.Block, options *Options) (*Presents, error) {
+//
+// Since the key of c is not known, the secret used by KeyedShuffle is derived from c, as described for Options.
+func NewWithCipher(c cipher.Block, options ...Option) (*Presents, error) {
+	return newWithKey(c, nil, options)
+}
+
+// newWithKey is NewWithCipher for constructors which know the key of c, from which it derives the secret.
+func newWithKey(c cipher.Block, key []byte, options []Option) (*Presents, error) {
+	cfg, err := newConfig(options)
+	if err != nil {
+		return nil, err
+	}
+	if key != nil {
+		cfg.secret = deriveSecret(key)
+	}
+	p, err := newWithCipher(c, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.selfTest {
+		if err := p.SelfTest(); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+func newWithCipher(c cipher.Block, cfg *config) (*Presents, error) {
 	if c.BlockSize() != 8 {
 		return nil, errors.New("presents: NewWithCipher: cipher should have a 64-bit block size")
 	}
 
+	options := &cfg.Options
+	pl := Pipeline{Permutation: cipherPermutation{block: c}}
+	if cfg.namespace != "" {
+		pl.Permutation = newNamespacePermutation(pl.Permutation, cfg.keySecret(c), cfg.namespace)
+	}
 	a := DefaultAlphabet
-	if options != nil {
-		if options.Alphabet != "" {
-			var err error
-			a, err = newAlphabet(options.Alphabet)
-			if err != nil {
-				return nil, err
-			}
+	alphabet, isAlphabet := options.Encoder.(Alphabet)
+	switch {
+	case isAlphabet && options.Alphabet != "":
+		return nil, errors.New("presents: NewWithCipher: Encoder and Alphabet are both set")
+	case isAlphabet:
+	case options.Encoder != nil && options.usesAlphabet():
+		return nil, errors.New("presents: NewWithCipher: Encoder cannot be combined with alphabet options")
+	default:
+		alphabet = Alphabet(options.Alphabet)
+	}
+	if alphabet != "" {
+		var err error
+		a, err = newAlphabet(string(alphabet))
+		if err != nil {
+			return nil, err
 		}
-		if options.Shuffle {
-			a = a.Shuffle(options.Seed)
+	}
+	if options.KeyedShuffle {
+		shuffleKey := cfg.shuffleKey
+		if shuffleKey == nil {
+			shuffleKey = cfg.keySecret(c)
 		}
+		a = a.KeyedShuffle(shuffleKey)
+	} else if options.Shuffle {
+		a = a.Shuffle(options.Seed)
+	}
+	if options.GroupSize < 0 {
+		return nil, errors.New("presents: GroupSize cannot be negative")
 	}
-	return &Presents{
-		cipher:   c,
-		alphabet: a,
-	}, nil
+	if options.Padding {
+		pl.Decorators = append(pl.Decorators, Padding())
+	}
+	if options.GroupSize > 0 {
+		pl.Decorators = append(pl.Decorators, Grouping{Size: options.GroupSize, Separator: options.Separator})
+	}
+	pl.Encoder = a
+	if options.Encoder != nil && !isAlphabet {
+		pl.Encoder = options.Encoder
+	}
+	p, err := pl.Build()
+	if err != nil {
+		return nil, err
+	}
+	p.constantTime = options.ConstantTime
+	p.maxID = options.MaxID
+	p.validate = options.Validate
+	p.canaries = options.Canaries
+	p.observer = options.Observer
+	p.blocklist = newBlocklist(options.Blocklist)
+	return p, nil
+}
+
+// usesAlphabet reports whether any of the options which customise the alphabet are set.
+func (o *Options) usesAlphabet() bool {
+	return o.Alphabet != "" || o.Shuffle || o.KeyedShuffle || o.ConstantTime ||
+		len(o.Blocklist) > 0 || o.Padding || o.GroupSize != 0
+}
+
+// shuffleKeySize is the size in bytes of the secrets used for KeyedShuffle.
+const shuffleKeySize = sha256.Size
+
+// deriveSecret derives the secret used by KeyedShuffle and WithNamespace from a cipher key.
+func deriveSecret(key []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("presents secret"))
+	return mac.Sum(nil)
+}
+
+// secretBlocks are decrypted to derive the secret used by KeyedShuffle and WithNamespace
+// from a cipher whose key is not known.
+// Wrap only ever encrypts, so its output does not reveal the decryptions.
+var secretBlocks = [4]uint64{
+	0x7072657365736563, 0x7072657365736564, 0x7072657365736565, 0x7072657365736566,
+}
+
+// keySecret returns the secret derived from the key by the constructor,
+// or if the key is not known, a secret derived by decrypting secretBlocks with c.
+func (cfg *config) keySecret(c cipher.Block) []byte {
+	if cfg.secret != nil {
+		return cfg.secret
+	}
+	secret := make([]byte, 8*len(secretBlocks))
+	for i, b := range secretBlocks {
+		block := secret[8*i : 8*i+8]
+		binary.BigEndian.PutUint64(block, b)
+		c.Decrypt(block, block)
+	}
+	return secret
 }
 
 // NewTripleDES creates a new Presents struct using Triple DES instead of PRESENT.
-// If options.Alphabet is not the empty string, it will be used as the alphabet.
-// If options.Shuffle is true, the alphabet will be shuffled based on options.Seed.
-func NewTripleDES(key []byte, options *Options) (*Presents, error) {
+// The options are as for New.
+func NewTripleDES(key []byte, options ...Option) (*Presents, error) {
 	c, err := des.NewTripleDESCipher(key)
 	if err != nil {
-		return nil, fmt.Errorf("presents: NewTripleDES: %v", err)
+		return nil, &KeySizeError{Cipher: "3des", Size: len(key), Valid: []int{24}, Err: err}
+	}
+	return newWithKey(c, key, options)
+}
+
+// AppendWrap appends the result of Wrap to dst and returns the extended buffer.
+// It avoids allocating a string when the Encoder is an AppendEncoder and no other stages or options are involved.
+func (p *Presents) AppendWrap(dst []byte, n uint64) []byte {
+	if p.observer != nil || p.blocklist != nil || p.integrity != nil || len(p.decorators) > 0 ||
+		p.permutation.Bits() < 64 {
+		return append(dst, p.Wrap(n)...)
+	}
+	x := p.encrypt(n)
+	if p.encoder == nil {
+		return p.alphabet.AppendEncode(dst, x)
 	}
-	return NewWithCipher(c, options)
+	if e, ok := p.encoder.(AppendEncoder); ok {
+		return e.AppendEncode(dst, x)
+	}
+	return append(dst, p.encoder.Encode(x)...)
 }
 
 // Wrap converts an unsigned 64-bit integer to a string.
+// It panics if n is outside the domain of a Permutation with fewer than 64 bits; TryWrap returns an error instead.
 func (p *Presents) Wrap(n uint64) string {
-	b := make([]byte, 8)
-	binary.BigEndian.PutUint64(b, n)
-	dst := make([]byte, 8)
-	p.cipher.Encrypt(dst, b)
-	n = binary.BigEndian.Uint64(dst)
-	return p.alphabet.Encode(n)
+	s, err := p.TryWrap(n)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// TryWrap is like Wrap, but returns ErrDomain if n is outside the domain of the Permutation.
+func (p *Presents) TryWrap(n uint64) (string, error) {
+	if bits := p.permutation.Bits(); bits < 64 && n>>uint(bits) != 0 {
+		return "", ErrDomain
+	}
+	if p.observer == nil {
+		return p.wrap(n), nil
+	}
+	start := time.Now()
+	s := p.wrap(n)
+	p.observer.Wrapped(time.Since(start))
+	return s, nil
+}
+
+func (p *Presents) wrap(n uint64) string {
+	return p.decorate(p.encode(p.seal(p.encrypt(n))))
+}
+
+// encrypt applies the permutation to n.
+func (p *Presents) encrypt(n uint64) uint64 {
+	return p.permutation.Permute(n)
+}
+
+// decrypt applies the inverse of the permutation to n.
+func (p *Presents) decrypt(n uint64) uint64 {
+	return p.permutation.Unpermute(n)
+}
+
+// seal appends the integrity tag of x to it, if there is an Integrity stage.
+func (p *Presents) seal(x uint64) uint64 {
+	if p.integrity == nil {
+		return x
+	}
+	return x<<uint(p.integrity.Bits()) | p.integrity.Tag(x)
+}
+
+// open checks and removes the integrity tag from v, and checks that the result is inside the domain of the permutation.
+// It returns ErrOverflow if v is too large to have been produced by seal, and ErrChecksum if the tag is wrong.
+func (p *Presents) open(v uint64) (uint64, error) {
+	bits := p.permutation.Bits()
+	if p.integrity != nil {
+		bits += p.integrity.Bits()
+	}
+	if bits < 64 && v>>uint(bits) != 0 {
+		return 0, ErrOverflow
+	}
+	if p.integrity == nil {
+		return v, nil
+	}
+	tagBits := uint(p.integrity.Bits())
+	x := v >> tagBits
+	if p.integrity.Tag(x) != v&mask(int(tagBits)) {
+		return 0, ErrChecksum
+	}
+	return x, nil
+}
+
+// decorate applies the decorators to s in order.
+func (p *Presents) decorate(s string) string {
+	for _, d := range p.decorators {
+		s = d.Decorate(s)
+	}
+	return s
+}
+
+// undecorate removes the decorators from s in reverse order.
+// It returns the intermediate strings in layers, where layers[i] is the input to decorators[i].Decorate,
+// so that errors can be traced back to positions in s by decoratedPos.
+func (p *Presents) undecorate(s string) (layers []string, err error) {
+	layers = make([]string, len(p.decorators)+1)
+	layers[len(p.decorators)] = s
+	for i := len(p.decorators) - 1; i >= 0; i-- {
+		layers[i], err = p.decorators[i].Undecorate(layers[i+1])
+		if err != nil {
+			return nil, p.decoratedError(err, layers, i+1)
+		}
+	}
+	return layers, nil
+}
+
+// decoratedError converts the position of an *InvalidCharError in layers[i] into a position in the original string.
+func (p *Presents) decoratedError(err error, layers []string, i int) error {
+	var invalidChar *InvalidCharError
+	if !errors.As(err, &invalidChar) {
+		return err
+	}
+	pos := invalidChar.Pos
+	for ; i < len(p.decorators); i++ {
+		if m, ok := p.decorators[i].(positionMapper); ok {
+			pos = m.decoratedPos(layers[i+1], pos)
+		}
+	}
+	return &InvalidCharError{Pos: pos, Char: invalidChar.Char}
 }
 
 // Unwrap converts a string back to an unsigned 64-bit integer.
-// It returns an error if the string cannot be converted using the given alphabet.
+// It returns an error if the string cannot be converted using the given alphabet,
+// or a *RejectedError if the result is rejected by Options.MaxID or Options.Validate.
 func (p *Presents) Unwrap(s string) (uint64, error) {
-	n, err := p.alphabet.Decode(s)
+	return p.UnwrapContext(context.Background(), s)
+}
+
+// UnwrapContext is like Unwrap, but passes ctx to the CanaryHandler if s is a canary token,
+// so that the handler can see request-scoped values such as the caller's identity.
+func (p *Presents) UnwrapContext(ctx context.Context, s string) (uint64, error) {
+	if p.observer == nil {
+		return p.unwrap(ctx, s)
+	}
+	start := time.Now()
+	n, err := p.unwrap(ctx, s)
+	p.observeUnwrap(err, start)
+	return n, err
+}
+
+// observeUnwrap tells the Observer the outcome of a call to Unwrap which started at start.
+func (p *Presents) observeUnwrap(err error, start time.Time) {
+	if err != nil {
+		p.observer.UnwrapFailed(ErrorKindOf(err), time.Since(start))
+	} else {
+		p.observer.Unwrapped(time.Since(start))
+	}
+}
+
+func (p *Presents) unwrap(ctx context.Context, s string) (uint64, error) {
+	n, err := p.parse(s)
 	if err != nil {
 		return 0, err
 	}
-	src := make([]byte, 8)
-	binary.BigEndian.PutUint64(src, n)
-	dst := make([]byte, 8)
-	p.cipher.Decrypt(dst, src)
-	n = binary.BigEndian.Uint64(dst)
+	return p.finish(ctx, n, s)
+}
+
+// parse undoes each stage of wrap, without the checks made by finish.
+func (p *Presents) parse(s string) (uint64, error) {
+	layers, err := p.undecorate(s)
+	if err != nil {
+		return 0, err
+	}
+	v, err := p.decode(layers[0])
+	if err != nil {
+		return 0, p.decoratedError(err, layers, 0)
+	}
+	x, err := p.open(v)
+	if err != nil {
+		return 0, err
+	}
+	return p.decrypt(x), nil
+}
+
+// finish reports canaries and applies Options.MaxID and Options.Validate to the ID n unwrapped from s.
+func (p *Presents) finish(ctx context.Context, n uint64, s string) (uint64, error) {
+	if p.canaries != nil {
+		// canaries are reported even if they would be rejected
+		p.canaries.notify(ctx, n, s)
+	}
+	if err := p.check(n); err != nil {
+		return 0, err
+	}
 	return n, nil
 }
+
+// check applies Options.MaxID and Options.Validate to an unwrapped ID.
+func (p *Presents) check(n uint64) error {
+	if p.maxID != 0 && n > p.maxID {
+		return &RejectedError{Err: ErrOutOfRange}
+	}
+	if p.validate != nil {
+		if err := p.validate(n); err != nil {
+			return &RejectedError{Err: err}
+		}
+	}
+	return nil
+}
+
+// WrapBatch converts each integer in src to a string and stores the results in dst.
+// It produces the same strings as calling Wrap on each element of src.
+//
+// When the Presents was created by New without WithNamespace, blocks are encrypted 64 at a time
+// using a bitsliced implementation of PRESENT.
+// Otherwise WrapBatch falls back to calling Wrap for each element.
+// WrapBatch panics if dst is shorter than src, or like Wrap if an element of src is outside the domain
+// of the Permutation; WrapAll reports such elements as errors instead.
+func (p *Presents) WrapBatch(dst []string, src []uint64) {
+	if len(dst) < len(src) {
+		panic("presents: WrapBatch: dst shorter than src")
+	}
+	if p.batch == nil {
+		for i, n := range src {
+			dst[i] = p.Wrap(n)
+		}
+		return
+	}
+	var blocks [64]uint64
+	for len(src) > 0 {
+		var start time.Time
+		if p.observer != nil {
+			start = time.Now()
+		}
+		k := copy(blocks[:], src)
+		p.batch.Encrypt64(&blocks)
+		for i, n := range blocks[:k] {
+			dst[i] = p.decorate(p.encode(n))
+		}
+		if p.observer != nil {
+			// each block in the chunk is reported with an equal share of its time
+			d := time.Since(start) / time.Duration(k)
+			for i := 0; i < k; i++ {
+				p.observer.Wrapped(d)
+			}
+		}
+		dst, src = dst[k:], src[k:]
+	}
+}
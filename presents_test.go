@@ -2,6 +2,7 @@ package presents_test
 
 import (
 	"log"
+	"math/rand"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -32,6 +33,18 @@ func TestPresents_Wrap(t *testing.T) {
 		expected := "w3CBcIAvNMd"
 		assert.Equal(t, expected, s)
 	})
+	t.Run("keyed shuffle", func(t *testing.T) {
+		key := make([]byte, 10)
+		p, err := presents.New(key, &presents.Options{
+			KeyedShuffle: true,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := p.Wrap(1213486160)
+		expected := "laQBmvF2dYp"
+		assert.Equal(t, expected, s)
+	})
 	t.Run("custom alphabet", func(t *testing.T) {
 		key := make([]byte, 10)
 		p, err := presents.New(key, &presents.Options{
@@ -75,6 +88,21 @@ func TestPresents_Unwrap(t *testing.T) {
 		var expected uint64 = 1213486160
 		assert.Equal(t, expected, s)
 	})
+	t.Run("keyed shuffle", func(t *testing.T) {
+		key := make([]byte, 10)
+		p, err := presents.New(key, &presents.Options{
+			KeyedShuffle: true,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		s, err := p.Unwrap("laQBmvF2dYp")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var expected uint64 = 1213486160
+		assert.Equal(t, expected, s)
+	})
 	t.Run("custom alphabet", func(t *testing.T) {
 		key := make([]byte, 10)
 		p, err := presents.New(key, &presents.Options{
@@ -211,3 +239,73 @@ func BenchmarkPresentsBlowFish_Wrap(b *testing.B) {
 		p.Wrap(uint64(i))
 	}
 }
+
+func TestPresents_WrapBatch(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	src := make([]uint64, 200)
+	for i := range src {
+		src[i] = r.Uint64()
+	}
+	src[0] = 1213486160
+	blowfishCipher, err := blowfish.NewCipher(make([]byte, 56))
+	if err != nil {
+		t.Fatal(err)
+	}
+	constructors := map[string]func() (*presents.Presents, error){
+		"80-bit key": func() (*presents.Presents, error) {
+			return presents.New(make([]byte, 10), nil)
+		},
+		"128-bit key": func() (*presents.Presents, error) {
+			return presents.New(make([]byte, 16), nil)
+		},
+		"shuffled": func() (*presents.Presents, error) {
+			return presents.New(make([]byte, 10), &presents.Options{Shuffle: true})
+		},
+		"triple des": func() (*presents.Presents, error) {
+			return presents.NewTripleDES(make([]byte, 24), nil)
+		},
+		"blowfish": func() (*presents.Presents, error) {
+			return presents.NewWithCipher(blowfishCipher, nil)
+		},
+	}
+	for name, constructor := range constructors {
+		t.Run(name, func(t *testing.T) {
+			p, err := constructor()
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, n := range []int{0, 1, 63, 64, 65, 200} {
+				dst := make([]string, n)
+				p.WrapBatch(dst, src[:n])
+				for i, s := range dst {
+					assert.Equal(t, p.Wrap(src[i]), s)
+				}
+			}
+		})
+	}
+	t.Run("dst too short", func(t *testing.T) {
+		p, err := presents.New(make([]byte, 10), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Panics(t, func() {
+			p.WrapBatch(make([]string, 1), src[:2])
+		})
+	})
+}
+
+func BenchmarkPresents_WrapBatch(b *testing.B) {
+	key := make([]byte, 10)
+	p, err := presents.New(key, nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+	src := make([]uint64, 64)
+	dst := make([]string, 64)
+	for i := 0; i < b.N; i += len(src) {
+		for j := range src {
+			src[j] = uint64(i + j)
+		}
+		p.WrapBatch(dst, src)
+	}
+}
@@ -0,0 +1,145 @@
+package presents
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+	"math/bits"
+)
+
+// PRINCE S-box, round constants and linear layer.
+var (
+	princeSBox    = [16]uint64{0xB, 0xF, 3, 2, 0xA, 0xC, 9, 1, 6, 7, 8, 0, 0xE, 5, 0xD, 4}
+	princeSBoxInv = invertSBox(princeSBox)
+	princeRC      = [12]uint64{
+		0x0000000000000000, 0x13198a2e03707344, 0xa4093822299f31d0, 0x082efa98ec4e6c89,
+		0x452821e638d01377, 0xbe5466cf34e90c6c, 0x7ef84f78fd955cb1, 0x85840851f1ac43aa,
+		0xc882d32f25323c54, 0x64a51195e0e3610d, 0xd3b5a399ca0c2399, 0xc0ac29b7c97c50dd,
+	}
+	// princeShiftRows maps each nibble of the output of SR, counting from the most significant, to its input nibble.
+	princeShiftRows = [16]uint{0, 5, 10, 15, 4, 9, 14, 3, 8, 13, 2, 7, 12, 1, 6, 11}
+	// princeMPrime holds the rows of the involutory matrix M', with bit 63 as the first column.
+	princeMPrime = mPrimeRows()
+)
+
+// princeAlpha is the constant relating PRINCE encryption and decryption.
+const princeAlpha = 0xc0ac29b7c97c50dd
+
+// princeBlock implements the PRINCE block cipher as defined by Borghoff et al. in
+// PRINCE – A Low-Latency Block Cipher for Pervasive Computing Applications (https://eprint.iacr.org/2012/529).
+//
+// Blocks and keys are big-endian, and the first 8 bytes of the key are k0.
+type princeBlock struct {
+	k0, k0Prime, k1 uint64
+}
+
+// newPRINCECipher returns a PRINCE cipher.Block for a 16-byte key.
+func newPRINCECipher(key []byte) (cipher.Block, error) {
+	if len(key) != 16 {
+		return nil, &KeySizeError{Cipher: "prince", Size: len(key), Valid: []int{16}}
+	}
+	k0 := binary.BigEndian.Uint64(key)
+	return &princeBlock{
+		k0:      k0,
+		k0Prime: bits.RotateLeft64(k0, -1) ^ k0>>63,
+		k1:      binary.BigEndian.Uint64(key[8:]),
+	}, nil
+}
+
+func (b *princeBlock) BlockSize() int {
+	return 8
+}
+
+func (b *princeBlock) Encrypt(dst, src []byte) {
+	if len(src) < 8 {
+		panic("presents: prince: input not full block")
+	}
+	if len(dst) < 8 {
+		panic("presents: prince: output not full block")
+	}
+	s := binary.BigEndian.Uint64(src)
+	s = princeCore(s^b.k0, b.k1) ^ b.k0Prime
+	binary.BigEndian.PutUint64(dst, s)
+}
+
+// Decrypt uses the α-reflection property of PRINCE:
+// decryption is encryption with k0 and k0' swapped and k1 replaced by k1 ⊕ α.
+func (b *princeBlock) Decrypt(dst, src []byte) {
+	if len(src) < 8 {
+		panic("presents: prince: input not full block")
+	}
+	if len(dst) < 8 {
+		panic("presents: prince: output not full block")
+	}
+	s := binary.BigEndian.Uint64(src)
+	s = princeCore(s^b.k0Prime, b.k1^princeAlpha) ^ b.k0
+	binary.BigEndian.PutUint64(dst, s)
+}
+
+// princeCore implements the 12-round PRINCEcore function.
+func princeCore(s, k1 uint64) uint64 {
+	s ^= k1 ^ princeRC[0]
+	for i := 1; i <= 5; i++ {
+		s = substitute(s, &princeSBox)
+		s = shiftRows(mPrime(s), false)
+		s ^= princeRC[i] ^ k1
+	}
+	s = substitute(s, &princeSBox)
+	s = mPrime(s)
+	s = substitute(s, &princeSBoxInv)
+	for i := 6; i <= 10; i++ {
+		s ^= princeRC[i] ^ k1
+		s = mPrime(shiftRows(s, true))
+		s = substitute(s, &princeSBoxInv)
+	}
+	return s ^ princeRC[11] ^ k1
+}
+
+// mPrime multiplies s by the matrix M'.
+func mPrime(s uint64) (result uint64) {
+	for i, row := range princeMPrime {
+		result |= uint64(bits.OnesCount64(s&row)&1) << uint(63-i)
+	}
+	return
+}
+
+// shiftRows applies SR to s, or its inverse if inverse is true.
+func shiftRows(s uint64, inverse bool) (result uint64) {
+	for i, j := range princeShiftRows {
+		src, dst := j, uint(i)
+		if inverse {
+			src, dst = dst, src
+		}
+		result |= (s >> (60 - 4*src) & 0xF) << (60 - 4*dst)
+	}
+	return
+}
+
+// mPrimeRows builds M' = diag(M̂0, M̂1, M̂1, M̂0), where each 16×16 matrix M̂ is a
+// 4×4 arrangement of the matrices M0, ..., M3 and Mj is the 4×4 identity with its jth diagonal entry cleared.
+// In M̂0 the block in row r and column c is M(r+c) mod 4, and in M̂1 it is M(r+c+1) mod 4.
+func mPrimeRows() (rows [64]uint64) {
+	for q, hat := range []int{0, 1, 1, 0} {
+		for r := 0; r < 4; r++ {
+			for c := 0; c < 4; c++ {
+				m := (r + c + hat) % 4
+				for d := 0; d < 4; d++ {
+					if d != m {
+						rows[16*q+4*r+d] |= 1 << uint(63-(16*q+4*c+d))
+					}
+				}
+			}
+		}
+	}
+	return
+}
+
+// NewPRINCE creates a new Presents struct using PRINCE instead of PRESENT.
+// The key should be 16 bytes long, with k0 in the first 8 bytes and k1 in the last 8.
+// The options are as for New.
+func NewPRINCE(key []byte, options ...Option) (*Presents, error) {
+	c, err := newPRINCECipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return newWithKey(c, key, options)
+}
@@ -0,0 +1,16 @@
+package presents
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPRINCE(t *testing.T) {
+	testKnownAnswers(t, newPRINCECipher, knownAnswers["prince"])
+}
+
+func TestNewPRINCECipher(t *testing.T) {
+	_, err := newPRINCECipher(make([]byte, 10))
+	assert.Equal(t, &KeySizeError{Cipher: "prince", Size: 10, Valid: []int{16}}, err)
+}
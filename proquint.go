@@ -0,0 +1,76 @@
+package presents
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	proquintConsonants = "bdfghjklmnprstvz"
+	proquintVowels     = "aiou"
+)
+
+// Proquint is an Encoder which writes 64-bit values as four proquints (https://arxiv.org/abs/0901.4016),
+// pronounceable five-letter words which each encode 16 bits, such as lusab-babad-gutih-tugad.
+// The most significant 16 bits come first. Decode ignores case.
+type Proquint struct{}
+
+// proquintLen is the length of four proquints joined by dashes.
+const proquintLen = 4*5 + 3
+
+func (q Proquint) Encode(n uint64) string {
+	return string(q.AppendEncode(make([]byte, 0, proquintLen), n))
+}
+
+// AppendEncode appends the result of Encode to dst and returns the extended buffer.
+func (Proquint) AppendEncode(b []byte, n uint64) []byte {
+	for i := 3; i >= 0; i-- {
+		q := n >> (16 * uint(i))
+		b = append(b,
+			proquintConsonants[q>>12&0xf],
+			proquintVowels[q>>10&0x3],
+			proquintConsonants[q>>6&0xf],
+			proquintVowels[q>>4&0x3],
+			proquintConsonants[q&0xf],
+		)
+		if i > 0 {
+			b = append(b, '-')
+		}
+	}
+	return b
+}
+
+// Decode converts four proquints back to a 64-bit value.
+// It returns ErrLength if s is not four dash-separated proquints long,
+// and an *InvalidCharError if it contains any other characters.
+func (Proquint) Decode(s string) (uint64, error) {
+	if len(s) != proquintLen {
+		return 0, ErrLength
+	}
+	var n uint64
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+		var x int
+		switch i % 6 {
+		case 5:
+			if c == '-' {
+				continue
+			}
+			x = -1
+		case 1, 3:
+			x = strings.IndexByte(proquintVowels, c)
+			n = n<<2 | uint64(x&3)
+		default:
+			x = strings.IndexByte(proquintConsonants, c)
+			n = n<<4 | uint64(x&0xf)
+		}
+		if x == -1 {
+			r, _ := utf8.DecodeRuneInString(s[i:])
+			return 0, &InvalidCharError{Pos: i, Char: r}
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,66 @@
+package presents_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yi-jiayu/presents"
+)
+
+func TestProquint(t *testing.T) {
+	testCases := []struct {
+		n        uint64
+		expected string
+	}{
+		// 127.0.0.1 and 63.84.220.193 from the proquint specification
+		{0x7f0000013f54dcc1, "lusab-babad-gutih-tugad"},
+		{0, "babab-babab-babab-babab"},
+		{1<<64 - 1, "zuzuz-zuzuz-zuzuz-zuzuz"},
+	}
+	var q presents.Proquint
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, q.Encode(tc.n))
+		n, err := q.Decode(tc.expected)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.n, n)
+	}
+	n, err := q.Decode("LUSAB-babad-GUTIH-tugad")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0x7f0000013f54dcc1), n)
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		n := r.Uint64()
+		actual, err := q.Decode(q.Encode(n))
+		assert.NoError(t, err)
+		assert.Equal(t, n, actual)
+	}
+}
+
+func TestProquint_Decode_errors(t *testing.T) {
+	var q presents.Proquint
+	_, err := q.Decode("lusab-babad")
+	assert.Equal(t, presents.ErrLength, err)
+	_, err = q.Decode("lusab-babad-gutih-tugaa")
+	assert.Equal(t, &presents.InvalidCharError{Pos: 22, Char: 'a'}, err)
+	_, err = q.Decode("lusab_babad-gutih-tugad")
+	assert.Equal(t, &presents.InvalidCharError{Pos: 5, Char: '_'}, err)
+	_, err = q.Decode("lusab-bebad-gutih-tugad")
+	assert.Equal(t, &presents.InvalidCharError{Pos: 7, Char: 'e'}, err)
+}
+
+func TestPresents_Wrap_proquint(t *testing.T) {
+	p, err := presents.New(make([]byte, 10), &presents.Options{Encoder: presents.Proquint{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := p.Wrap(1213486160)
+	assert.Len(t, s, 23)
+	n, err := p.Unwrap(s)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1213486160), n)
+
+	_, err = presents.New(make([]byte, 10), &presents.Options{Encoder: presents.Proquint{}, GroupSize: 4})
+	assert.EqualError(t, err, "presents: NewWithCipher: Encoder cannot be combined with alphabet options")
+}
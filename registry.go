@@ -0,0 +1,129 @@
+package presents
+
+import (
+	"crypto/cipher"
+	"fmt"
+	"sort"
+	"sync"
+
+	"golang.org/x/crypto/blowfish"
+)
+
+// CipherFunc creates a cipher.Block with a 64-bit block size from a key.
+type CipherFunc func(key []byte) (cipher.Block, error)
+
+// registeredCipher describes a cipher that can be constructed by name.
+type registeredCipher struct {
+	keySizes    []int
+	newPresents func(key []byte, options ...Option) (*Presents, error)
+}
+
+var (
+	ciphersMu sync.RWMutex
+	ciphers   = make(map[string]registeredCipher)
+)
+
+func init() {
+	register("present-80", []int{10}, New)
+	register("present-128", []int{16}, New)
+	register("3des", []int{24}, NewTripleDES)
+	register("speck64/96", []int{12}, NewSpeck)
+	register("speck64/128", []int{16}, NewSpeck)
+	register("simon64/128", []int{16}, NewSimon)
+	register("gift64/128", []int{16}, NewGIFT)
+	register("prince", []int{16}, NewPRINCE)
+	blowfishKeySizes := make([]int, 56)
+	for i := range blowfishKeySizes {
+		blowfishKeySizes[i] = i + 1
+	}
+	Register("blowfish", blowfishKeySizes, func(key []byte) (cipher.Block, error) {
+		return blowfish.NewCipher(key)
+	})
+}
+
+// Register makes a cipher available to NewFromSpec under the provided name.
+// keySizes lists the key lengths in bytes accepted by newCipher,
+// which NewFromSpec checks before calling it.
+// Register panics if name is already registered, or if newCipher is nil.
+func Register(name string, keySizes []int, newCipher CipherFunc) {
+	if newCipher == nil {
+		panic("presents: Register: cipher constructor is nil")
+	}
+	register(name, keySizes, func(key []byte, options ...Option) (*Presents, error) {
+		c, err := newCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("presents: NewFromSpec: %w", err)
+		}
+		return newWithKey(c, key, options)
+	})
+}
+
+func register(name string, keySizes []int, newPresents func(key []byte, options ...Option) (*Presents, error)) {
+	ciphersMu.Lock()
+	defer ciphersMu.Unlock()
+	if _, ok := ciphers[name]; ok {
+		panic("presents: Register called twice for cipher " + name)
+	}
+	ciphers[name] = registeredCipher{
+		keySizes:    append([]int(nil), keySizes...),
+		newPresents: newPresents,
+	}
+}
+
+// unregister removes the cipher registered under name, so that tests can clean up after Register.
+func unregister(name string) {
+	ciphersMu.Lock()
+	defer ciphersMu.Unlock()
+	delete(ciphers, name)
+}
+
+// Ciphers returns a sorted list of the names of the registered ciphers.
+func Ciphers() []string {
+	ciphersMu.RLock()
+	defer ciphersMu.RUnlock()
+	names := make([]string, 0, len(ciphers))
+	for name := range ciphers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// cipherKeySizes returns the key sizes accepted by the cipher registered under name in ascending order,
+// or nil if there is no such cipher.
+func cipherKeySizes(name string) []int {
+	ciphersMu.RLock()
+	defer ciphersMu.RUnlock()
+	c, ok := ciphers[name]
+	if !ok {
+		return nil
+	}
+	keySizes := append([]int(nil), c.keySizes...)
+	sort.Ints(keySizes)
+	return keySizes
+}
+
+// NewFromSpec creates a new Presents struct using the cipher registered under the name spec.
+// The built-in ciphers are present-80, present-128, 3des, blowfish, speck64/96, speck64/128,
+// simon64/128, gift64/128 and prince.
+// It returns a *KeySizeError if key has the wrong length for the cipher.
+// The options are as for New.
+func NewFromSpec(spec string, key []byte, options ...Option) (*Presents, error) {
+	ciphersMu.RLock()
+	c, ok := ciphers[spec]
+	ciphersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownCipher, spec)
+	}
+	valid := false
+	for _, n := range c.keySizes {
+		if len(key) == n {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return nil, &KeySizeError{Cipher: spec, Size: len(key), Valid: c.keySizes}
+	}
+	return c.newPresents(key, options...)
+}
@@ -0,0 +1,101 @@
+package presents_test
+
+import (
+	"crypto/cipher"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yi-jiayu/presents"
+)
+
+func TestNewFromSpec(t *testing.T) {
+	t.Run("built-in ciphers", func(t *testing.T) {
+		keySizes := map[string]int{
+			"present-80":  10,
+			"present-128": 16,
+			"3des":        24,
+			"blowfish":    56,
+			"speck64/96":  12,
+			"speck64/128": 16,
+			"simon64/128": 16,
+			"gift64/128":  16,
+			"prince":      16,
+		}
+		for name, size := range keySizes {
+			p, err := presents.NewFromSpec(name, make([]byte, size), nil)
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			n, err := p.Unwrap(p.Wrap(1213486160))
+			assert.NoError(t, err, name)
+			assert.Equal(t, uint64(1213486160), n, name)
+		}
+	})
+	t.Run("same output as constructors", func(t *testing.T) {
+		p, err := presents.NewFromSpec("present-80", make([]byte, 10), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, "90NyXHLckhA", p.Wrap(1213486160))
+		p, err = presents.NewFromSpec("3des", make([]byte, 24), &presents.Options{Shuffle: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, "D7sYkicVqvE", p.Wrap(1213486160))
+	})
+	t.Run("invalid key size", func(t *testing.T) {
+		_, err := presents.NewFromSpec("present-80", make([]byte, 16), nil)
+		assert.Equal(t, &presents.KeySizeError{Cipher: "present-80", Size: 16, Valid: []int{10}}, err)
+		assert.EqualError(t, err, "presents: invalid key size 16 for cipher present-80 (want 10 bytes)")
+		_, err = presents.NewFromSpec("blowfish", make([]byte, 57), nil)
+		assert.EqualError(t, err, "presents: invalid key size 57 for cipher blowfish (want 1-56 bytes)")
+	})
+	t.Run("unknown cipher", func(t *testing.T) {
+		_, err := presents.NewFromSpec("rot13", make([]byte, 10), nil)
+		assert.EqualError(t, err, `presents: unknown cipher "rot13"`)
+		assert.True(t, errors.Is(err, presents.ErrUnknownCipher))
+	})
+}
+
+// xorBlock is a toy cipher.Block for testing Register.
+type xorBlock byte
+
+func (b xorBlock) BlockSize() int {
+	return 8
+}
+
+func (b xorBlock) Encrypt(dst, src []byte) {
+	for i := 0; i < 8; i++ {
+		dst[i] = src[i] ^ byte(b)
+	}
+}
+
+func (b xorBlock) Decrypt(dst, src []byte) {
+	b.Encrypt(dst, src)
+}
+
+func TestRegister(t *testing.T) {
+	t.Cleanup(func() { presents.Unregister("test-xor") })
+	presents.Register("test-xor", []int{1}, func(key []byte) (cipher.Block, error) {
+		if key[0] == 0 {
+			return nil, errors.New("weak key")
+		}
+		return xorBlock(key[0]), nil
+	})
+	assert.Contains(t, presents.Ciphers(), "test-xor")
+	p, err := presents.NewFromSpec("test-xor", []byte{0xFF}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := p.Unwrap(p.Wrap(1213486160))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1213486160), n)
+	_, err = presents.NewFromSpec("test-xor", []byte{0}, nil)
+	assert.EqualError(t, err, "presents: NewFromSpec: weak key")
+	assert.Panics(t, func() {
+		presents.Register("test-xor", []int{1}, func(key []byte) (cipher.Block, error) {
+			return xorBlock(key[0]), nil
+		})
+	})
+}
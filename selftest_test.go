@@ -0,0 +1,118 @@
+package presents
+
+import (
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckKnownAnswers(t *testing.T) {
+	assert.NoError(t, checkKnownAnswers())
+	for name, vectors := range knownAnswers {
+		for _, v := range vectors {
+			assert.NoError(t, checkKnownAnswer(name, v))
+		}
+	}
+
+	err := checkKnownAnswer("present-80", knownAnswer{"00000000000000000000", "0000000000000000", "0000000000000000"})
+	assert.True(t, errors.Is(err, ErrSelfTest))
+	assert.EqualError(t, err, "presents: self-test failed: present-80 encrypts 0000000000000000 "+
+		"with key 00000000000000000000 to 5579c1387b228445, not 0000000000000000")
+}
+
+// faultyCipher is a cipher.Block whose Decrypt is not the inverse of Encrypt for some blocks.
+type faultyCipher struct {
+	identity bool
+}
+
+func (faultyCipher) BlockSize() int {
+	return 8
+}
+
+func (c faultyCipher) Encrypt(dst, src []byte) {
+	x := binary.BigEndian.Uint64(src)
+	if !c.identity {
+		x = x*0x9e3779b97f4a7c15 + 1
+	}
+	binary.BigEndian.PutUint64(dst, x)
+}
+
+func (c faultyCipher) Decrypt(dst, src []byte) {
+	x := binary.BigEndian.Uint64(src)
+	if !c.identity {
+		// the inverse of the multiplication is missing
+		x--
+	}
+	binary.BigEndian.PutUint64(dst, x)
+}
+
+// truncatingEncoder loses the top bit of the integers it encodes.
+type truncatingEncoder struct{}
+
+func (truncatingEncoder) Encode(n uint64) string {
+	return DefaultAlphabet.Encode(n &^ (1 << 63))
+}
+
+func (truncatingEncoder) Decode(s string) (uint64, error) {
+	return DefaultAlphabet.Decode(s)
+}
+
+func TestPresents_SelfTest(t *testing.T) {
+	key := make([]byte, 16)
+	// only the built-in ciphers, since other tests may register ciphers of their own
+	for name := range knownAnswers {
+		p, err := NewFromSpec(name, make([]byte, cipherKeySizes(name)[0]), WithSelfTest())
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		assert.NoError(t, p.SelfTest(), name)
+	}
+
+	codecs := map[string][]Option{
+		"padding":   {WithAlphabet("0123456789"), WithPadding(), WithGrouping(3, '-')},
+		"blocklist": {WithAlphabet("ab"), WithBlocklist("aaa", "bbb")},
+		"namespace": {WithNamespace("users"), WithKeyedShuffle()},
+		"encoder":   {WithEncoder(Proquint{})},
+		"legacy":    {&Options{Alphabet: "abcdef", ConstantTime: true}},
+	}
+	for name, options := range codecs {
+		_, err := New(key, append(options, WithSelfTest())...)
+		assert.NoError(t, err, name)
+	}
+
+	perm, err := NewFeistel(key, 36)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum, err := NewChecksum(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	digits, err := NewFixedWidth("0123456789", 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := Pipeline{Permutation: perm, Integrity: sum, Encoder: digits}.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, p.SelfTest())
+}
+
+func TestPresents_SelfTest_failures(t *testing.T) {
+	_, err := NewWithCipher(faultyCipher{}, WithSelfTest())
+	assert.True(t, errors.Is(err, ErrSelfTest))
+	assert.EqualError(t, err, "presents: self-test failed: Unpermute(Permute(0x1)) = 0x9e3779b97f4a7c15")
+
+	// without WithSelfTest the faulty cipher is not detected
+	_, err = NewWithCipher(faultyCipher{})
+	assert.NoError(t, err)
+
+	_, err = NewWithCipher(faultyCipher{identity: true}, WithSelfTest())
+	assert.EqualError(t, err, "presents: self-test failed: Permute does not change any of the 23 samples")
+
+	_, err = New(make([]byte, 10), WithEncoder(truncatingEncoder{}), WithSelfTest())
+	assert.EqualError(t, err, "presents: self-test failed: encoding of 0x8000000000000000 decodes to 0x0 (error <nil>)")
+}
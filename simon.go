@@ -0,0 +1,89 @@
+package presents
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+	"math/bits"
+)
+
+// simonRounds is the number of rounds in SIMON64/128.
+const simonRounds = 44
+
+// simonZ3 is the constant sequence z3 used by the SIMON64/128 key schedule, most significant bit first.
+const simonZ3 uint64 = 0x36eb19781229cd0f
+
+// simonBlock implements the SIMON64/128 block cipher as defined by Beaulieu et al. in
+// The SIMON and SPECK Families of Lightweight Block Ciphers (https://eprint.iacr.org/2013/404).
+//
+// Blocks and keys use the same byte order as speckBlock.
+type simonBlock struct {
+	roundKeys [simonRounds]uint32
+}
+
+// newSimonCipher returns a SIMON64/128 cipher.Block for a 16-byte key.
+func newSimonCipher(key []byte) (cipher.Block, error) {
+	if len(key) != 16 {
+		return nil, &KeySizeError{Cipher: "simon64/128", Size: len(key), Valid: []int{16}}
+	}
+	var b simonBlock
+	k := b.roundKeys[:]
+	for i := 0; i < 4; i++ {
+		k[i] = binary.BigEndian.Uint32(key[12-4*i:])
+	}
+	for i := 4; i < simonRounds; i++ {
+		tmp := bits.RotateLeft32(k[i-1], -3) ^ k[i-3]
+		tmp ^= bits.RotateLeft32(tmp, -1)
+		z := uint32(simonZ3 >> uint(61-(i-4)%62) & 1)
+		k[i] = ^k[i-4] ^ tmp ^ z ^ 3
+	}
+	return &b, nil
+}
+
+func simonF(x uint32) uint32 {
+	return bits.RotateLeft32(x, 1)&bits.RotateLeft32(x, 8) ^ bits.RotateLeft32(x, 2)
+}
+
+func (b *simonBlock) BlockSize() int {
+	return 8
+}
+
+func (b *simonBlock) Encrypt(dst, src []byte) {
+	if len(src) < 8 {
+		panic("presents: simon: input not full block")
+	}
+	if len(dst) < 8 {
+		panic("presents: simon: output not full block")
+	}
+	x, y := binary.BigEndian.Uint32(src), binary.BigEndian.Uint32(src[4:])
+	for _, k := range b.roundKeys {
+		x, y = y^simonF(x)^k, x
+	}
+	binary.BigEndian.PutUint32(dst, x)
+	binary.BigEndian.PutUint32(dst[4:], y)
+}
+
+func (b *simonBlock) Decrypt(dst, src []byte) {
+	if len(src) < 8 {
+		panic("presents: simon: input not full block")
+	}
+	if len(dst) < 8 {
+		panic("presents: simon: output not full block")
+	}
+	x, y := binary.BigEndian.Uint32(src), binary.BigEndian.Uint32(src[4:])
+	for i := simonRounds - 1; i >= 0; i-- {
+		x, y = y, x^simonF(y)^b.roundKeys[i]
+	}
+	binary.BigEndian.PutUint32(dst, x)
+	binary.BigEndian.PutUint32(dst[4:], y)
+}
+
+// NewSimon creates a new Presents struct using SIMON64/128 instead of PRESENT.
+// The key should be 16 bytes long.
+// The options are as for New.
+func NewSimon(key []byte, options ...Option) (*Presents, error) {
+	c, err := newSimonCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return newWithKey(c, key, options)
+}
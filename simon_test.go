@@ -0,0 +1,16 @@
+package presents
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimon(t *testing.T) {
+	testKnownAnswers(t, newSimonCipher, knownAnswers["simon64/128"])
+}
+
+func TestNewSimonCipher(t *testing.T) {
+	_, err := newSimonCipher(make([]byte, 12))
+	assert.Equal(t, &KeySizeError{Cipher: "simon64/128", Size: 12, Valid: []int{16}}, err)
+}
@@ -0,0 +1,36 @@
+package presents
+
+import (
+	"crypto/cipher"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testKnownAnswers checks that ciphers created by newCipher encrypt and decrypt each vector as expected.
+func testKnownAnswers(t *testing.T, newCipher func([]byte) (cipher.Block, error), vectors []knownAnswer) {
+	for _, v := range vectors {
+		key, _ := hex.DecodeString(v.key)
+		plaintext, _ := hex.DecodeString(v.plaintext)
+		c, err := newCipher(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dst := make([]byte, 8)
+		c.Encrypt(dst, plaintext)
+		assert.Equal(t, v.ciphertext, hex.EncodeToString(dst), "key %s", v.key)
+		c.Decrypt(dst, dst)
+		assert.Equal(t, v.plaintext, hex.EncodeToString(dst), "key %s", v.key)
+	}
+}
+
+func TestSpeck(t *testing.T) {
+	testKnownAnswers(t, newSpeckCipher, knownAnswers["speck64/96"])
+	testKnownAnswers(t, newSpeckCipher, knownAnswers["speck64/128"])
+}
+
+func TestNewSpeckCipher(t *testing.T) {
+	_, err := newSpeckCipher(make([]byte, 10))
+	assert.EqualError(t, err, "presents: invalid key size 10 for cipher speck64 (want 12, 16 bytes)")
+}
@@ -0,0 +1,176 @@
+package presents
+
+import (
+	"errors"
+	"strings"
+)
+
+// sqidsDefaultAlphabet is the default Sqids alphabet.
+const sqidsDefaultAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// Sqids decodes strings produced by Sqids (https://sqids.org/),
+// so that legacy IDs can still be read while migrating to presents.
+//
+// Decode only accepts the strings the encoder would produce, which depend on its blocklist:
+// when an ID contains a blocked word, the encoder generates a different one instead.
+// The minimum length of the encoder does not need to be known.
+type Sqids struct {
+	alphabet  string
+	blocklist []string
+}
+
+// NewSqids returns a Sqids decoder for IDs produced with the provided alphabet and blocklist.
+// If alphabet is the empty string, the default Sqids alphabet is used.
+// The blocklist should be the one the encoder used, including its default list if it had one;
+// IDs which the encoder re-generated to avoid a word missing from blocklist are rejected by Decode.
+func NewSqids(alphabet string, blocklist ...string) (*Sqids, error) {
+	if alphabet == "" {
+		alphabet = sqidsDefaultAlphabet
+	}
+	if len(alphabet) < 3 {
+		return nil, errors.New("presents: NewSqids: alphabet must contain at least 3 characters")
+	}
+	for i := 0; i < len(alphabet); i++ {
+		if alphabet[i] >= 0x80 {
+			return nil, errors.New("presents: NewSqids: alphabet cannot contain multibyte characters")
+		}
+		if strings.IndexByte(alphabet[i+1:], alphabet[i]) != -1 {
+			return nil, errors.New("presents: NewSqids: alphabet must contain unique characters")
+		}
+	}
+	q := &Sqids{alphabet: string(sqidsShuffle([]byte(alphabet)))}
+	// like the encoder, ignore words which are too short or cannot appear in an ID
+	lowerAlphabet := strings.ToLower(alphabet)
+	for _, word := range blocklist {
+		word = strings.ToLower(word)
+		if len(word) >= 3 && strings.Trim(word, lowerAlphabet) == "" {
+			q.blocklist = append(q.blocklist, word)
+		}
+	}
+	return q, nil
+}
+
+// sqidsShuffle permutes alphabet in place and returns it.
+func sqidsShuffle(alphabet []byte) []byte {
+	for i, j := 0, len(alphabet)-1; j > 0; i, j = i+1, j-1 {
+		r := (i*j + int(alphabet[i]) + int(alphabet[j])) % len(alphabet)
+		alphabet[i], alphabet[r] = alphabet[r], alphabet[i]
+	}
+	return alphabet
+}
+
+// Decode returns the numbers encoded in s.
+// It returns an error if s was not produced by a Sqids encoder with the same alphabet and blocklist,
+// or encodes a number which does not fit in a uint64.
+func (q *Sqids) Decode(s string) ([]uint64, error) {
+	invalid := errors.New("presents: sqids: invalid input")
+	if s == "" {
+		return nil, invalid
+	}
+	for i := 0; i < len(s); i++ {
+		if strings.IndexByte(q.alphabet, s[i]) == -1 {
+			return nil, invalid
+		}
+	}
+	offset := strings.IndexByte(q.alphabet, s[0])
+	alphabet := []byte(q.alphabet[offset:] + q.alphabet[:offset])
+	for i, j := 0, len(alphabet)-1; i < j; i, j = i+1, j-1 {
+		alphabet[i], alphabet[j] = alphabet[j], alphabet[i]
+	}
+	var numbers []uint64
+	id := s[1:]
+	for id != "" {
+		separator := alphabet[0]
+		chunk := id
+		rest := ""
+		hasSeparator := false
+		if k := strings.IndexByte(id, separator); k != -1 {
+			chunk, rest, hasSeparator = id[:k], id[k+1:], true
+		}
+		if chunk == "" {
+			// the remainder is padding
+			break
+		}
+		n, ok := parseDigits(chunk, alphabet[1:])
+		if !ok {
+			return nil, invalid
+		}
+		numbers = append(numbers, n)
+		if hasSeparator {
+			alphabet = sqidsShuffle(alphabet)
+		}
+		id = rest
+	}
+	if numbers == nil || q.encode(numbers, len(s)) != s {
+		return nil, invalid
+	}
+	return numbers, nil
+}
+
+// encode implements the Sqids encoding algorithm, padding the result to minLength. It is used to check the results of Decode.
+// The encoder's minimum length is not known, but padding to the length of the decoded string
+// reproduces it exactly if it was padded, and has no effect otherwise.
+func (q *Sqids) encode(numbers []uint64, minLength int) string {
+	n := len(q.alphabet)
+	offset := len(numbers)
+	for i, v := range numbers {
+		offset += int(q.alphabet[v%uint64(n)]) + i
+	}
+	offset %= n
+	for increment := 0; increment <= n; increment++ {
+		id := q.encodeIncrement(numbers, (offset+increment)%n, minLength)
+		if !q.blocked(id) {
+			return id
+		}
+	}
+	return ""
+}
+
+// encodeIncrement encodes numbers starting from the rotation of the alphabet given by offset.
+func (q *Sqids) encodeIncrement(numbers []uint64, offset, minLength int) string {
+	alphabet := []byte(q.alphabet[offset:] + q.alphabet[:offset])
+	id := []byte{alphabet[0]}
+	for i, j := 0, len(alphabet)-1; i < j; i, j = i+1, j-1 {
+		alphabet[i], alphabet[j] = alphabet[j], alphabet[i]
+	}
+	for i, v := range numbers {
+		id = append(id, hashidsToAlphabet(v, alphabet[1:])...)
+		if i < len(numbers)-1 {
+			id = append(id, alphabet[0])
+			alphabet = sqidsShuffle(alphabet)
+		}
+	}
+	if len(id) < minLength {
+		id = append(id, alphabet[0])
+		for len(id) < minLength {
+			alphabet = sqidsShuffle(alphabet)
+			k := minLength - len(id)
+			if k > len(alphabet) {
+				k = len(alphabet)
+			}
+			id = append(id, alphabet[:k]...)
+		}
+	}
+	return string(id)
+}
+
+// blocked reports whether the encoder would reject id because of its blocklist.
+func (q *Sqids) blocked(id string) bool {
+	id = strings.ToLower(id)
+	for _, word := range q.blocklist {
+		switch {
+		case len(word) > len(id):
+		case len(id) <= 3 || len(word) <= 3:
+			if id == word {
+				return true
+			}
+		case strings.ContainsAny(word, "0123456789"):
+			if strings.HasPrefix(id, word) || strings.HasSuffix(id, word) {
+				return true
+			}
+		case strings.Contains(id, word):
+			return true
+		}
+	}
+	return false
+}
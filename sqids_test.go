@@ -0,0 +1,69 @@
+package presents
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSqids_Decode(t *testing.T) {
+	q, err := NewSqids("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	vectors := map[string][]uint64{
+		"86Rf07":     {1, 2, 3},
+		"86Rf07xd4z": {1, 2, 3},
+		"bM":         {0},
+		"Uk":         {1},
+		"gb":         {2},
+		"nJ":         {9},
+	}
+	for id, expected := range vectors {
+		numbers, err := q.Decode(id)
+		assert.NoError(t, err, id)
+		assert.Equal(t, expected, numbers, id)
+	}
+}
+
+func TestSqids_Decode_invalid(t *testing.T) {
+	q, err := NewSqids("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the last three decode to numbers, but are not what the encoder would produce
+	for _, id := range []string{"", "86Rf07!", "8", "8zzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzz", "uzsKqyW14", "Ul", "86Rf07xd4a"} {
+		_, err := q.Decode(id)
+		assert.Error(t, err, id)
+	}
+}
+
+func TestNewSqids(t *testing.T) {
+	_, err := NewSqids("ab")
+	assert.EqualError(t, err, "presents: NewSqids: alphabet must contain at least 3 characters")
+	_, err = NewSqids("abca")
+	assert.EqualError(t, err, "presents: NewSqids: alphabet must contain unique characters")
+	_, err = NewSqids("abcé")
+	assert.EqualError(t, err, "presents: NewSqids: alphabet cannot contain multibyte characters")
+}
+
+func TestSqids_Decode_blocklist(t *testing.T) {
+	q, err := NewSqids("", "ArUO")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 100000 encodes to "ArUO", which the encoder replaces because it is blocked
+	_, err = q.Decode("ArUO")
+	assert.Error(t, err)
+	numbers, err := q.Decode("QyG4")
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{100000}, numbers)
+
+	q, err = NewSqids("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	numbers, err = q.Decode("ArUO")
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{100000}, numbers)
+}
@@ -0,0 +1,103 @@
+package presents
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"strings"
+)
+
+// DefaultWordSeparator separates the words written by a WordList when no separator is given.
+const DefaultWordSeparator = "-"
+
+// WordList is an Encoder which writes 64-bit values as a fixed number of words,
+// most significant first, using the words of a list as the digits.
+// With a 2048-word list such as DefaultWordList, every value takes six words.
+// Decode ignores case.
+type WordList struct {
+	words     []string
+	index     map[string]int
+	separator string
+	length    int
+}
+
+// DefaultWordList returns a WordList using the 2048 words of the BIP 39 English word list, separated by dashes.
+func DefaultWordList() *WordList {
+	w, err := NewWordList(bip39English, DefaultWordSeparator)
+	if err != nil {
+		panic(err)
+	}
+	return w
+}
+
+// NewWordList returns a WordList which uses words as its digits, separated by separator,
+// or DefaultWordSeparator if separator is empty.
+// There must be at least two words, they must be unique ignoring case,
+// and none of them may be empty or contain the separator.
+func NewWordList(words []string, separator string) (*WordList, error) {
+	if len(words) < 2 {
+		return nil, errors.New("presents: NewWordList: need at least 2 words")
+	}
+	if separator == "" {
+		separator = DefaultWordSeparator
+	}
+	w := &WordList{
+		words:     make([]string, len(words)),
+		index:     make(map[string]int, len(words)),
+		separator: separator,
+	}
+	for i, word := range words {
+		if word == "" || strings.Contains(word, separator) {
+			return nil, fmt.Errorf("presents: NewWordList: word %q is empty or contains the separator %q", word, separator)
+		}
+		lower := strings.ToLower(word)
+		if _, ok := w.index[lower]; ok {
+			return nil, fmt.Errorf("presents: NewWordList: word %q appears more than once", word)
+		}
+		w.words[i] = lower
+		w.index[lower] = i
+	}
+	// enough words for the largest value
+	for n := ^uint64(0); n > 0; n /= uint64(len(words)) {
+		w.length++
+	}
+	return w, nil
+}
+
+func (w *WordList) Encode(n uint64) string {
+	b := uint64(len(w.words))
+	words := make([]string, w.length)
+	for i := len(words) - 1; i >= 0; i-- {
+		words[i] = w.words[n%b]
+		n /= b
+	}
+	return strings.Join(words, w.separator)
+}
+
+// Decode converts a string of words back to a 64-bit value.
+// It returns ErrLength if s does not have the right number of words,
+// an error matching ErrInvalidChar if it contains a word which is not in the list,
+// and ErrOverflow if the value does not fit in 64 bits.
+func (w *WordList) Decode(s string) (uint64, error) {
+	words := strings.Split(s, w.separator)
+	if len(words) != w.length {
+		return 0, ErrLength
+	}
+	b := uint64(len(w.words))
+	var n uint64
+	pos := 0
+	for _, word := range words {
+		x, ok := w.index[strings.ToLower(word)]
+		if !ok {
+			return 0, fmt.Errorf("%w: unknown word %q at position %d", ErrInvalidChar, word, pos)
+		}
+		hi, lo := bits.Mul64(n, b)
+		var carry uint64
+		n, carry = bits.Add64(lo, uint64(x), 0)
+		if hi != 0 || carry != 0 {
+			return 0, ErrOverflow
+		}
+		pos += len(word) + len(w.separator)
+	}
+	return n, nil
+}
@@ -0,0 +1,57 @@
+package presents
+
+import (
+	"errors"
+	"hash/crc32"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBIP39English(t *testing.T) {
+	// checksum of english.txt from the BIP 39 repository
+	assert.Equal(t, uint32(0xc1dbd296), crc32.ChecksumIEEE([]byte(strings.Join(bip39English, "\n")+"\n")))
+}
+
+func TestWordList(t *testing.T) {
+	w := DefaultWordList()
+	assert.Equal(t, "abandon-abandon-abandon-abandon-abandon-abandon", w.Encode(0))
+	assert.Equal(t, "abandon-abandon-abandon-abandon-abandon-ability", w.Encode(1))
+	assert.Equal(t, "divide-zoo-zoo-zoo-zoo-zoo", w.Encode(1<<64-1))
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		n := r.Uint64()
+		actual, err := w.Decode(w.Encode(n))
+		assert.NoError(t, err)
+		assert.Equal(t, n, actual)
+	}
+	n, err := w.Decode("Abandon-ABANDON-abandon-abandon-abandon-ability")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), n)
+}
+
+func TestWordList_Decode_errors(t *testing.T) {
+	w := DefaultWordList()
+	_, err := w.Decode("abandon-ability")
+	assert.Equal(t, ErrLength, err)
+	_, err = w.Decode("abandon-abandon-bananas-abandon-abandon-ability")
+	assert.True(t, errors.Is(err, ErrInvalidChar))
+	assert.EqualError(t, err, `presents: invalid character: unknown word "bananas" at position 16`)
+	_, err = w.Decode("zoo-zoo-zoo-zoo-zoo-zoo")
+	assert.Equal(t, ErrOverflow, err)
+}
+
+func TestNewWordList(t *testing.T) {
+	w, err := NewWordList([]string{"Red", "green", "blue"}, " ")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "red red red red red red red red red red red red red red red red red red red red red red red red red red red red red red red red red red red red red red red red green", w.Encode(1))
+	}
+	_, err = NewWordList([]string{"one"}, "")
+	assert.Error(t, err)
+	_, err = NewWordList([]string{"one", "ONE"}, "")
+	assert.EqualError(t, err, `presents: NewWordList: word "ONE" appears more than once`)
+	_, err = NewWordList([]string{"one", "two-three"}, "")
+	assert.EqualError(t, err, `presents: NewWordList: word "two-three" is empty or contains the separator "-"`)
+}